@@ -0,0 +1,143 @@
+package collector
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Shard states reported even if there are no shards in them
+var shardStates = []string{"STARTED", "INITIALIZING", "RELOCATING", "UNASSIGNED"}
+
+type AllocationCollector struct {
+	client *Client
+	logger *logrus.Logger
+
+	datePattern string
+	explain     bool
+
+	shards      *prometheus.Desc
+	unassigned  *prometheus.Desc
+	explainInfo *prometheus.Desc
+}
+
+func NewAllocationCollector(logger *logrus.Logger, client *Client, labels []string, datepattern string, explain bool,
+	constLabels prometheus.Labels) *AllocationCollector {
+
+	return &AllocationCollector{
+		client:      client,
+		logger:      logger,
+		datePattern: datepattern,
+		explain:     explain,
+		shards: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index", "shards"),
+			"Count of primary and replica shards in each state of each index to date", append(labels, "prirep", "state"), constLabels,
+		),
+		unassigned: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index", "unassigned_shards"),
+			"Count of unassigned shards of each index to date by the unassigned reason", append(labels, "reason"), constLabels,
+		),
+		explainInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index_allocation_explain", "info"),
+			"Allocation explanation of the first unassigned primary shard of each red index to date",
+			append(labels, "shard", "can_allocate", "decider", "explanation"), constLabels,
+		),
+	}
+}
+
+func (c *AllocationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.shards
+	ch <- c.unassigned
+	ch <- c.explainInfo
+}
+
+func (c *AllocationCollector) Collect(ch chan<- prometheus.Metric) {
+	today := todayFunc(c.datePattern)
+	indicesPattern := indicesPatternFunc(today)
+
+	shards, err := c.client.GetShards([]string{indicesPattern})
+	if err != nil {
+		c.logger.Errorf("error getting shards: %v", err)
+		return
+	}
+
+	counts := make(map[string]map[string]float64)
+	reasons := make(map[string]map[string]float64)
+	// The first unassigned primary shard of each red index
+	red := make(map[string]ShardInfo)
+	for _, shard := range shards {
+		if _, ok := counts[shard.Index]; !ok {
+			counts[shard.Index] = make(map[string]float64)
+		}
+		counts[shard.Index][shardPrirep(shard.Prirep)+"/"+shard.State]++
+
+		if shard.State != "UNASSIGNED" {
+			continue
+		}
+		if _, ok := reasons[shard.Index]; !ok {
+			reasons[shard.Index] = make(map[string]float64)
+		}
+		reasons[shard.Index][shard.UnassignedReason]++
+
+		if _, ok := red[shard.Index]; !ok && shard.Prirep == "p" {
+			red[shard.Index] = shard
+		}
+	}
+
+	for index, count := range counts {
+		indexGroup := indexGroupLabelFunc(index, today)
+		for _, prirep := range []string{"primary", "replica"} {
+			for _, state := range shardStates {
+				ch <- prometheus.MustNewConstMetric(c.shards, prometheus.GaugeValue, count[prirep+"/"+state], index, indexGroup, prirep, state)
+			}
+		}
+	}
+
+	for index, reason := range reasons {
+		indexGroup := indexGroupLabelFunc(index, today)
+		for r, v := range reason {
+			ch <- prometheus.MustNewConstMetric(c.unassigned, prometheus.GaugeValue, v, index, indexGroup, r)
+		}
+	}
+
+	if !c.explain {
+		return
+	}
+	for index, shard := range red {
+		n, err := strconv.Atoi(shard.Shard)
+		if err != nil {
+			c.logger.Errorf("got invalid shard number %q for: %s", shard.Shard, index)
+			continue
+		}
+
+		explain, err := c.client.ExplainAllocation(index, n, true)
+		if err != nil {
+			c.logger.Errorf("error explaining allocation of %s shard %d: %v", index, n, err)
+			continue
+		}
+
+		decider, explanation := allocationDecider(explain)
+		ch <- prometheus.MustNewConstMetric(c.explainInfo, prometheus.GaugeValue, 1,
+			index, indexGroupLabelFunc(index, today), shard.Shard, explain.CanAllocate, decider, explanation)
+	}
+}
+
+// Return the first decider which prevents the allocation, or the overall explanation if there are none
+func allocationDecider(explain AllocationExplain) (string, string) {
+	for _, node := range explain.NodeAllocationDecisions {
+		for _, d := range node.Deciders {
+			if d.Decision == "NO" {
+				return d.Decider, d.Explanation
+			}
+		}
+	}
+	return "", explain.AllocateExplanation
+}
+
+func shardPrirep(prirep string) string {
+	if prirep == "p" {
+		return "primary"
+	}
+	return "replica"
+}
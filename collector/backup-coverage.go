@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+type BackupCoverageCollector struct {
+	client *Client
+	logger *logrus.Logger
+
+	repo        string
+	datePattern string
+	// Count of days to check, starting from yesterday: today's indices are usually not in a snapshot yet
+	days int
+	// Indices older than this are expected to be in a successful snapshot
+	minAge time.Duration
+
+	covered     *prometheus.Desc
+	unprotected *prometheus.Desc
+}
+
+func NewBackupCoverageCollector(logger *logrus.Logger, client *Client, repo string, datepattern string, days int, minAge time.Duration,
+	constLabels prometheus.Labels) *BackupCoverageCollector {
+
+	return &BackupCoverageCollector{
+		client:      client,
+		logger:      logger,
+		repo:        repo,
+		datePattern: datepattern,
+		days:        days,
+		minAge:      minAge,
+		covered: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "backup_coverage", "covered"),
+			"Indices of each index group and date are in at least one successful snapshot", []string{"repository", "index_group", "date"}, constLabels,
+		),
+		unprotected: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "backup_coverage", "unprotected_indices"),
+			"Count of dated indices older than the minimal age which are not in any successful snapshot", []string{"repository"}, constLabels,
+		),
+	}
+}
+
+func (c *BackupCoverageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.covered
+	ch <- c.unprotected
+}
+
+func (c *BackupCoverageCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+
+	// Indices of the checked days can only be in snapshots started after they were created
+	snapshots, err := c.client.GetSnapshotsSince(c.repo, now.AddDate(0, 0, -(c.days+1)))
+	if err != nil {
+		c.logger.Errorf("error getting snapshots in %s: %v", c.repo, err)
+		return
+	}
+
+	protected := make(map[string]bool)
+	for _, s := range snapshots {
+		if s.State != "SUCCESS" {
+			continue
+		}
+		for _, index := range s.Indices {
+			protected[index] = true
+		}
+	}
+
+	patterns := make([]string, 0, c.days)
+	dates := make(map[string]string, c.days)
+	for i := 1; i <= c.days; i++ {
+		day := now.AddDate(0, 0, -i).Format(c.datePattern)
+		patterns = append(patterns, indicesPatternFunc(day))
+		dates[indicesPatternFunc(day)] = day
+	}
+
+	settings, err := c.client.GetSettings(patterns)
+	if err != nil {
+		c.logger.Errorf("error getting indices settings: %v", err)
+		return
+	}
+
+	type groupDate struct{ group, date string }
+	covered := make(map[groupDate]bool)
+	var unprotected float64
+	for index, v := range settings {
+		day := ""
+		for pattern, d := range dates {
+			if simpleMatch(pattern, index) {
+				day = d
+				break
+			}
+		}
+		if day == "" {
+			continue
+		}
+
+		key := groupDate{indexGroupLabelFunc(index, day), day}
+		if ok, seen := covered[key]; !seen || ok {
+			covered[key] = protected[index]
+		}
+
+		if protected[index] {
+			continue
+		}
+
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			c.logger.Errorf("got invalid index setttings for: %s", index)
+			continue
+		}
+		s, ok := indexSetting(data, "index.creation_date")
+		if !ok {
+			c.logger.Errorf("%q was not found for: %s", "index.creation_date", index)
+			continue
+		}
+		created, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			c.logger.Errorf("error parsing %q value for: %s: %v ", "index.creation_date", index, err)
+			continue
+		}
+		if now.Sub(time.Unix(0, created*int64(time.Millisecond))) > c.minAge {
+			c.logger.Debugf("Index %s is not in any successful snapshot in %s", index, c.repo)
+			unprotected++
+		}
+	}
+
+	for k, ok := range covered {
+		var v float64
+		if ok {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.covered, prometheus.GaugeValue, v, c.repo, k.group, k.date)
+	}
+	ch <- prometheus.MustNewConstMetric(c.unprotected, prometheus.GaugeValue, unprotected, c.repo)
+}
@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Entries of indices which were not requested during this period are dropped
+const metadataCacheTTL = time.Hour
+
+// Maximum count of changed indices fetched by their names, keeps the URL within http.max_initial_line_length
+const metadataFetchLimit = 20
+
+type metadataCacheEntry struct {
+	version  int64
+	value    interface{}
+	lastUsed time.Time
+}
+
+// Cache of per index metadata (mapping, settings) keyed by its metadata version
+type metadataCache struct {
+	mu      sync.Mutex
+	entries map[string]metadataCacheEntry
+
+	hits   float64
+	misses float64
+}
+
+func newMetadataCache() *metadataCache {
+	return &metadataCache{entries: make(map[string]metadataCacheEntry)}
+}
+
+// Return cached values of the indices with unchanged versions and fetch the rest.
+// Indices without a version (older Elasticsearch) are always fetched.
+func (c *metadataCache) get(versions map[string]int64,
+	fetch func([]string) (map[string]interface{}, error)) (map[string]interface{}, error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	result := make(map[string]interface{}, len(versions))
+
+	var stale []string
+	for index, version := range versions {
+		if e, ok := c.entries[index]; ok && version > 0 && e.version == version {
+			e.lastUsed = now
+			c.entries[index] = e
+			result[index] = e.value
+			c.hits++
+		} else {
+			stale = append(stale, index)
+			c.misses++
+		}
+	}
+
+	if len(stale) > 0 {
+		fetched, err := fetch(stale)
+		if err != nil {
+			return nil, err
+		}
+		for index, v := range fetched {
+			result[index] = v
+			if version := versions[index]; version > 0 {
+				c.entries[index] = metadataCacheEntry{version: version, value: v, lastUsed: now}
+			}
+		}
+	}
+
+	for index, e := range c.entries {
+		if now.Sub(e.lastUsed) > metadataCacheTTL {
+			delete(c.entries, index)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *metadataCache) stats() (hits, misses float64, size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.hits, c.misses, len(c.entries)
+}
+
+// Shards of all indices are requested by several collectors, which share the response during a scrape
+const shardsCacheTTL = 5 * time.Second
+
+type shardsCache struct {
+	mu      sync.Mutex
+	shards  []ShardInfo
+	fetched time.Time
+}
+
+// Return the shards fetched during the last TTL, or fetch them. Concurrent callers wait for a single fetch.
+func (c *shardsCache) get(fetch func() ([]ShardInfo, error)) ([]ShardInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.fetched) < shardsCacheTTL {
+		return c.shards, nil
+	}
+
+	shards, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.shards, c.fetched = shards, time.Now()
+
+	return shards, nil
+}
+
+type CacheCollector struct {
+	client *Client
+	logger *logrus.Logger
+
+	hits    *prometheus.Desc
+	misses  *prometheus.Desc
+	entries *prometheus.Desc
+}
+
+func NewCacheCollector(logger *logrus.Logger, client *Client, labels []string,
+	constLabels prometheus.Labels) *CacheCollector {
+
+	return &CacheCollector{
+		client: client,
+		logger: logger,
+		hits: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "metadata_cache", "hits_total"),
+			"Count of indices served from the metadata cache", labels, constLabels,
+		),
+		misses: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "metadata_cache", "misses_total"),
+			"Count of indices fetched because of a changed metadata version", labels, constLabels,
+		),
+		entries: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "metadata_cache", "entries"),
+			"Count of indices in the metadata cache", labels, constLabels,
+		),
+	}
+}
+
+func (c *CacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.entries
+}
+
+func (c *CacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, cache := range map[string]*metadataCache{
+		"mapping":  c.client.mappingCache,
+		"settings": c.client.settingsCache,
+	} {
+		hits, misses, size := cache.stats()
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, hits, name)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, misses, name)
+		ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(size), name)
+	}
+}
@@ -1,10 +1,14 @@
 package collector
 
 import (
+	"bytes"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 
 	elasticsearch "github.com/elastic/go-elasticsearch/v7"
 	"github.com/sirupsen/logrus"
@@ -13,6 +17,14 @@ import (
 type Client struct {
 	es     *elasticsearch.Client
 	logger *logrus.Logger
+
+	// Version of the cluster, set once it's known
+	version esVersion
+
+	mappingCache  *metadataCache
+	settingsCache *metadataCache
+	snapshotCache *snapshotCache
+	shardsCache   *shardsCache
 }
 
 type IndexHealthInfo struct {
@@ -21,6 +33,221 @@ type IndexHealthInfo struct {
 	NumberOfReplicas int    `json:"number_of_replicas"`
 }
 
+type Snapshot struct {
+	Snapshot          string   `json:"snapshot"`
+	State             string   `json:"state"`
+	Indices           []string `json:"indices"`
+	StartTimeInMillis float64  `json:"start_time_in_millis"`
+	EndTimeInMillis   float64  `json:"end_time_in_millis"`
+	DurationInMillis  float64  `json:"duration_in_millis"`
+	Failures          []struct {
+		Index   string `json:"index"`
+		ShardID int    `json:"shard_id"`
+		Reason  string `json:"reason"`
+	} `json:"failures"`
+	Shards SnapshotShards `json:"shards"`
+}
+
+type SnapshotShards struct {
+	Total      float64 `json:"total"`
+	Failed     float64 `json:"failed"`
+	Successful float64 `json:"successful"`
+}
+
+// Snapshot without the lists of its indices and failures
+type SnapshotSummary struct {
+	Snapshot          string
+	State             string
+	StartTimeInMillis float64
+	EndTimeInMillis   float64
+	DurationInMillis  float64
+	Failures          int
+	Shards            SnapshotShards
+}
+
+func (s Snapshot) summary() SnapshotSummary {
+	return SnapshotSummary{
+		Snapshot:          s.Snapshot,
+		State:             s.State,
+		StartTimeInMillis: s.StartTimeInMillis,
+		EndTimeInMillis:   s.EndTimeInMillis,
+		DurationInMillis:  s.DurationInMillis,
+		Failures:          len(s.Failures),
+		Shards:            s.Shards,
+	}
+}
+
+type SnapshotStatus struct {
+	Snapshot    string `json:"snapshot"`
+	Repository  string `json:"repository"`
+	State       string `json:"state"`
+	ShardsStats struct {
+		Done  float64 `json:"done"`
+		Total float64 `json:"total"`
+	} `json:"shards_stats"`
+	Stats struct {
+		Incremental struct {
+			FileCount   float64 `json:"file_count"`
+			SizeInBytes float64 `json:"size_in_bytes"`
+		} `json:"incremental"`
+		Processed struct {
+			FileCount   float64 `json:"file_count"`
+			SizeInBytes float64 `json:"size_in_bytes"`
+		} `json:"processed"`
+		Total struct {
+			FileCount   float64 `json:"file_count"`
+			SizeInBytes float64 `json:"size_in_bytes"`
+		} `json:"total"`
+		StartTimeInMillis float64 `json:"start_time_in_millis"`
+		TimeInMillis      float64 `json:"time_in_millis"`
+	} `json:"stats"`
+}
+
+type ShardRecovery struct {
+	Type              string  `json:"type"`
+	Stage             string  `json:"stage"`
+	StartTimeInMillis float64 `json:"start_time_in_millis"`
+	TotalTimeInMillis float64 `json:"total_time_in_millis"`
+	Source            struct {
+		Repository string `json:"repository"`
+		Snapshot   string `json:"snapshot"`
+	} `json:"source"`
+	Index struct {
+		Size struct {
+			TotalInBytes     float64 `json:"total_in_bytes"`
+			RecoveredInBytes float64 `json:"recovered_in_bytes"`
+		} `json:"size"`
+	} `json:"index"`
+}
+
+type Repository struct {
+	Type     string                 `json:"type"`
+	Settings map[string]interface{} `json:"settings"`
+}
+
+type SLMPolicy struct {
+	Policy struct {
+		Name       string `json:"name"`
+		Schedule   string `json:"schedule"`
+		Repository string `json:"repository"`
+	} `json:"policy"`
+	LastSuccess *struct {
+		SnapshotName string  `json:"snapshot_name"`
+		Time         float64 `json:"time"`
+	} `json:"last_success"`
+	LastFailure *struct {
+		SnapshotName string  `json:"snapshot_name"`
+		Time         float64 `json:"time"`
+		Details      string  `json:"details"`
+	} `json:"last_failure"`
+	NextExecutionMillis float64 `json:"next_execution_millis"`
+}
+
+type SLMPolicyStats struct {
+	Policy                   string  `json:"policy"`
+	SnapshotsTaken           float64 `json:"snapshots_taken"`
+	SnapshotsFailed          float64 `json:"snapshots_failed"`
+	SnapshotsDeleted         float64 `json:"snapshots_deleted"`
+	SnapshotDeletionFailures float64 `json:"snapshot_deletion_failures"`
+}
+
+type ShardInfo struct {
+	Index  string `json:"index"`
+	Shard  string `json:"shard"`
+	Prirep string `json:"prirep"`
+	State  string `json:"state"`
+	Node   string `json:"node"`
+	Store  string `json:"store"`
+
+	UnassignedReason string `json:"unassigned.reason"`
+}
+
+type AllocationExplain struct {
+	CanAllocate             string `json:"can_allocate"`
+	AllocateExplanation     string `json:"allocate_explanation"`
+	NodeAllocationDecisions []struct {
+		NodeName string `json:"node_name"`
+		Deciders []struct {
+			Decider     string `json:"decider"`
+			Decision    string `json:"decision"`
+			Explanation string `json:"explanation"`
+		} `json:"deciders"`
+	} `json:"node_allocation_decisions"`
+}
+
+type NodeInfo struct {
+	Name       string            `json:"name"`
+	Host       string            `json:"host"`
+	IP         string            `json:"ip"`
+	Roles      []string          `json:"roles"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+type NodeFsStats struct {
+	Name string `json:"name"`
+	Fs   struct {
+		Total struct {
+			TotalInBytes     float64 `json:"total_in_bytes"`
+			FreeInBytes      float64 `json:"free_in_bytes"`
+			AvailableInBytes float64 `json:"available_in_bytes"`
+		} `json:"total"`
+	} `json:"fs"`
+}
+
+type ILMIndexExplain struct {
+	Managed         bool    `json:"managed"`
+	Policy          string  `json:"policy"`
+	Phase           string  `json:"phase"`
+	PhaseTimeMillis float64 `json:"phase_time_millis"`
+	Action          string  `json:"action"`
+	Step            string  `json:"step"`
+	FailedStep      string  `json:"failed_step"`
+	StepInfo        struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"step_info"`
+}
+
+type IndexTemplate struct {
+	IndexPatterns []string `json:"index_patterns"`
+	Priority      int64    `json:"priority"`
+	Version       *int64   `json:"version"`
+}
+
+type LegacyTemplate struct {
+	Order         int                    `json:"order"`
+	IndexPatterns []string               `json:"index_patterns"`
+	Settings      map[string]interface{} `json:"settings"`
+}
+
+type FieldUsageStats struct {
+	Shards []struct {
+		Stats struct {
+			Fields map[string]FieldUsage `json:"fields"`
+		} `json:"stats"`
+	} `json:"shards"`
+}
+
+type FieldUsage struct {
+	Any           float64 `json:"any"`
+	InvertedIndex struct {
+		Terms float64 `json:"terms"`
+	} `json:"inverted_index"`
+	DocValues float64 `json:"doc_values"`
+	Points    float64 `json:"points"`
+}
+
+// Return the usage by queries and aggregations: term lookups, doc values and points.
+// Unlike `any` it doesn't count fetching of stored fields.
+func (u FieldUsage) Queries() float64 {
+	return u.InvertedIndex.Terms + u.DocValues + u.Points
+}
+
+type IndexMetadataVersions struct {
+	MappingVersion  int64 `json:"mapping_version"`
+	SettingsVersion int64 `json:"settings_version"`
+}
+
 func NewClient(logger *logrus.Logger, addresses []string, tlsClientConfig *tls.Config) (*Client, error) {
 	cfg := elasticsearch.Config{
 		Addresses: addresses,
@@ -34,14 +261,23 @@ func NewClient(logger *logrus.Logger, addresses []string, tlsClientConfig *tls.C
 		return nil, err
 	}
 
-	return &Client{es: es, logger: logger}, nil
+	return &Client{
+		es:            es,
+		logger:        logger,
+		mappingCache:  newMetadataCache(),
+		settingsCache: newMetadataCache(),
+		snapshotCache: newSnapshotCache(),
+		shardsCache:   &shardsCache{},
+	}, nil
 
 }
 
+// Return indices stats including per shard stats under `shards`
 func (c *Client) GetIndices(s []string) (map[string]interface{}, error) {
 	c.logger.Debug("Getting indices stats: ", s)
 	resp, err := c.es.Indices.Stats(
 		c.es.Indices.Stats.WithIndex(s...),
+		c.es.Indices.Stats.WithLevel("shards"),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error getting response: %s", err)
@@ -60,7 +296,27 @@ func (c *Client) GetIndices(s []string) (map[string]interface{}, error) {
 	return r["indices"], nil
 }
 
-func (c *Client) GetSnapshots(sr string) (map[string][]interface{}, error) {
+// Return summaries of all snapshots of the repository. Since 7.14 snapshots are fetched with the paginated API
+// and cached, so only new and in-progress snapshots are fetched on each call.
+func (c *Client) GetSnapshots(sr string) ([]SnapshotSummary, error) {
+	if c.version.atLeast(7, 14) {
+		return c.snapshotCache.get(c, sr)
+	}
+
+	snapshots, err := c.getAllSnapshots(sr)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]SnapshotSummary, 0, len(snapshots))
+	for _, s := range snapshots {
+		result = append(result, s.summary())
+	}
+
+	return result, nil
+}
+
+func (c *Client) getAllSnapshots(sr string) ([]Snapshot, error) {
 	c.logger.Debug("Getting snapshots in: ", sr)
 	resp, err := c.es.Snapshot.Get(sr, []string{"*"})
 	if err != nil {
@@ -72,7 +328,129 @@ func (c *Client) GetSnapshots(sr string) (map[string][]interface{}, error) {
 		return nil, fmt.Errorf("request failed: %v", resp.String())
 	}
 
-	var r map[string][]interface{}
+	var r struct {
+		Snapshots []Snapshot `json:"snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.Snapshots, nil
+}
+
+func (c *Client) GetSnapshotsStatus(repo string, snapshots []string) ([]SnapshotStatus, error) {
+	c.logger.Debug("Getting snapshots status in ", repo, ": ", snapshots)
+	resp, err := c.es.Snapshot.Status(
+		c.es.Snapshot.Status.WithRepository(repo),
+		c.es.Snapshot.Status.WithSnapshot(snapshots...),
+		c.es.Snapshot.Status.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		Snapshots []SnapshotStatus `json:"snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.Snapshots, nil
+}
+
+// Return the status of snapshots which are currently running in any repository
+func (c *Client) GetRunningSnapshotsStatus() ([]SnapshotStatus, error) {
+	c.logger.Debug("Getting running snapshots status")
+	resp, err := c.es.Snapshot.Status()
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		Snapshots []SnapshotStatus `json:"snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.Snapshots, nil
+}
+
+// Return shard recoveries of the indices, nil means all indices
+func (c *Client) GetRecovery(indices []string, activeOnly bool) (map[string][]ShardRecovery, error) {
+	c.logger.Debug("Getting indices recovery: ", indices)
+	resp, err := c.es.Indices.Recovery(
+		c.es.Indices.Recovery.WithIndex(indices...),
+		c.es.Indices.Recovery.WithActiveOnly(activeOnly),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r map[string]struct {
+		Shards []ShardRecovery `json:"shards"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]ShardRecovery, len(r))
+	for index, v := range r {
+		result[index] = v.Shards
+	}
+
+	return result, nil
+}
+
+func (c *Client) GetRepositories() (map[string]Repository, error) {
+	c.logger.Debug("Getting snapshot repositories")
+	resp, err := c.es.Snapshot.GetRepository()
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r map[string]Repository
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (c *Client) GetSLMPolicies() (map[string]SLMPolicy, error) {
+	c.logger.Debug("Getting SLM policies")
+	resp, err := c.es.SlmGetLifecycle()
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r map[string]SLMPolicy
 	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
 		return nil, err
 	}
@@ -80,6 +458,73 @@ func (c *Client) GetSnapshots(sr string) (map[string][]interface{}, error) {
 	return r, nil
 }
 
+func (c *Client) GetSLMStats() ([]SLMPolicyStats, error) {
+	c.logger.Debug("Getting SLM stats")
+	resp, err := c.es.SlmGetStats()
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		PolicyStats []SLMPolicyStats `json:"policy_stats"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.PolicyStats, nil
+}
+
+func (c *Client) GetSLMStatus() (string, error) {
+	c.logger.Debug("Getting SLM status")
+	resp, err := c.es.SlmGetStatus()
+	if err != nil {
+		return "", fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return "", fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		OperationMode string `json:"operation_mode"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return "", err
+	}
+
+	return r.OperationMode, nil
+}
+
+// Verify the repository and return the count of nodes which verified it
+func (c *Client) VerifyRepository(repo string) (int, error) {
+	c.logger.Debug("Verifying snapshot repository: ", repo)
+	resp, err := c.es.Snapshot.VerifyRepository(repo)
+	if err != nil {
+		return 0, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return 0, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		Nodes map[string]interface{} `json:"nodes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return 0, err
+	}
+
+	return len(r.Nodes), nil
+}
+
 func (c *Client) GetInfo() (map[string]interface{}, error) {
 	c.logger.Debug("Getting cluster info")
 	resp, err := c.es.Info()
@@ -100,10 +545,29 @@ func (c *Client) GetInfo() (map[string]interface{}, error) {
 	return r, nil
 }
 
+// Return indices mapping, refetching only the indices with changed mapping version
 func (c *Client) GetMapping(s []string) (map[string]interface{}, error) {
+	versions, err := c.GetIndicesVersions(s)
+	if err != nil {
+		return nil, err
+	}
+
+	v := make(map[string]int64, len(versions))
+	for index, iv := range versions {
+		v[index] = iv.MappingVersion
+	}
+
+	return c.mappingCache.get(v, func(stale []string) (map[string]interface{}, error) {
+		return c.getMapping(staleIndices(s, stale))
+	})
+}
+
+func (c *Client) getMapping(s []string) (map[string]interface{}, error) {
 	c.logger.Debug("Getting indices mapping: ", s)
 	resp, err := c.es.Indices.GetMapping(
 		c.es.Indices.GetMapping.WithIndex(s...),
+		c.es.Indices.GetMapping.WithIgnoreUnavailable(true),
+		c.es.Indices.GetMapping.WithAllowNoIndices(true),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error getting response: %s", err)
@@ -122,11 +586,39 @@ func (c *Client) GetMapping(s []string) (map[string]interface{}, error) {
 	return r, nil
 }
 
+// Return indices settings, refetching only the indices with changed settings version
 func (c *Client) GetSettings(s []string) (map[string]interface{}, error) {
+	versions, err := c.GetIndicesVersions(s)
+	if err != nil {
+		return nil, err
+	}
+
+	v := make(map[string]int64, len(versions))
+	for index, iv := range versions {
+		v[index] = iv.SettingsVersion
+	}
+
+	return c.settingsCache.get(v, func(stale []string) (map[string]interface{}, error) {
+		return c.getSettings(staleIndices(s, stale))
+	})
+}
+
+// Return the names of the changed indices to refetch, or the original patterns if there are too many of them
+// to fit into the URL, e.g. on the first scrape
+func staleIndices(patterns, stale []string) []string {
+	if len(stale) > metadataFetchLimit {
+		return patterns
+	}
+	return stale
+}
+
+func (c *Client) getSettings(s []string) (map[string]interface{}, error) {
 	c.logger.Debug("Getting indices settings: ", s)
 	resp, err := c.es.Indices.GetSettings(
 		c.es.Indices.GetSettings.WithIndex(s...),
 		c.es.Indices.GetSettings.WithIncludeDefaults(true),
+		c.es.Indices.GetSettings.WithIgnoreUnavailable(true),
+		c.es.Indices.GetSettings.WithAllowNoIndices(true),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error getting response: %s", err)
@@ -145,6 +637,39 @@ func (c *Client) GetSettings(s []string) (map[string]interface{}, error) {
 	return r, nil
 }
 
+func (c *Client) GetIndicesVersions(s []string) (map[string]IndexMetadataVersions, error) {
+	c.logger.Debug("Getting indices metadata versions: ", s)
+	resp, err := c.es.Cluster.State(
+		c.es.Cluster.State.WithMetric("metadata"),
+		c.es.Cluster.State.WithIndex(s...),
+		c.es.Cluster.State.WithFilterPath(
+			"metadata.indices.*.mapping_version",
+			"metadata.indices.*.settings_version",
+			// Always present, keeps indices in the response on versions without metadata versions
+			"metadata.indices.*.state",
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		Metadata struct {
+			Indices map[string]IndexMetadataVersions `json:"indices"`
+		} `json:"metadata"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.Metadata.Indices, nil
+}
+
 func (c *Client) GetClusterSettings() (map[string]interface{}, error) {
 	c.logger.Debug("Getting cluster settings")
 	resp, err := c.es.Cluster.GetSettings(
@@ -167,6 +692,185 @@ func (c *Client) GetClusterSettings() (map[string]interface{}, error) {
 	return r, nil
 }
 
+// Return effective cluster settings including defaults, limited by filter_path
+func (c *Client) GetClusterSettingsWithDefaults(filter ...string) (map[string]interface{}, error) {
+	c.logger.Debug("Getting cluster settings with defaults: ", filter)
+	resp, err := c.es.Cluster.GetSettings(
+		c.es.Cluster.GetSettings.WithIncludeDefaults(true),
+		c.es.Cluster.GetSettings.WithFilterPath(filter...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Explain why the shard of the index is unassigned
+func (c *Client) ExplainAllocation(index string, shard int, primary bool) (AllocationExplain, error) {
+	c.logger.Debugf("Explaining allocation of %s shard %d", index, shard)
+	body, err := json.Marshal(map[string]interface{}{
+		"index":   index,
+		"shard":   shard,
+		"primary": primary,
+	})
+	if err != nil {
+		return AllocationExplain{}, err
+	}
+
+	resp, err := c.es.Cluster.AllocationExplain(
+		c.es.Cluster.AllocationExplain.WithBody(bytes.NewReader(body)),
+	)
+	if err != nil {
+		return AllocationExplain{}, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return AllocationExplain{}, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r AllocationExplain
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return AllocationExplain{}, err
+	}
+
+	return r, nil
+}
+
+// Return names of closed indices
+func (c *Client) GetClosedIndices() (map[string]bool, error) {
+	c.logger.Debug("Getting closed indices")
+	resp, err := c.es.Cat.Indices(
+		c.es.Cat.Indices.WithExpandWildcards("closed"),
+		c.es.Cat.Indices.WithFormat("json"),
+		c.es.Cat.Indices.WithH("index", "status"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r []struct {
+		Index  string `json:"index"`
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	closed := make(map[string]bool)
+	for _, i := range r {
+		if i.Status == "close" {
+			closed[i.Index] = true
+		}
+	}
+
+	return closed, nil
+}
+
+// Return shards of all indices, the response is shared by the collectors during a scrape.
+// The returned slice must not be modified.
+func (c *Client) GetAllShards() ([]ShardInfo, error) {
+	return c.shardsCache.get(func() ([]ShardInfo, error) {
+		return c.GetShards(nil)
+	})
+}
+
+func (c *Client) GetShards(indices []string) ([]ShardInfo, error) {
+	c.logger.Debug("Getting shards: ", indices)
+	resp, err := c.es.Cat.Shards(
+		c.es.Cat.Shards.WithIndex(indices...),
+		c.es.Cat.Shards.WithFormat("json"),
+		c.es.Cat.Shards.WithBytes("b"),
+		c.es.Cat.Shards.WithH("index", "shard", "prirep", "state", "node", "store", "unassigned.reason"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r []ShardInfo
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	// Relocating shards are reported as `source -> ip id target`, keep the source node
+	for i := range r {
+		if n := strings.Index(r[i].Node, " -> "); n >= 0 {
+			r[i].Node = r[i].Node[:n]
+		}
+	}
+
+	return r, nil
+}
+
+func (c *Client) GetNodes() (map[string]NodeInfo, error) {
+	c.logger.Debug("Getting nodes info")
+	resp, err := c.es.Nodes.Info(
+		c.es.Nodes.Info.WithFilterPath("nodes.*.name", "nodes.*.host", "nodes.*.ip", "nodes.*.roles", "nodes.*.attributes"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		Nodes map[string]NodeInfo `json:"nodes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.Nodes, nil
+}
+
+func (c *Client) GetNodesFsStats() (map[string]NodeFsStats, error) {
+	c.logger.Debug("Getting nodes fs stats")
+	resp, err := c.es.Nodes.Stats(
+		c.es.Nodes.Stats.WithMetric("fs"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		Nodes map[string]NodeFsStats `json:"nodes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.Nodes, nil
+}
+
 func (c *Client) GetIndicesHealth(indices []string) (map[string]IndexHealthInfo, error) {
 	resp, err := c.es.Cluster.Health(
 		c.es.Cluster.Health.WithIndex(indices...),
@@ -187,3 +891,150 @@ func (c *Client) GetIndicesHealth(indices []string) (map[string]IndexHealthInfo,
 	}
 	return body.Indices, nil
 }
+
+// Perform the request to an API which is missing in the client and decode the response into v
+func (c *Client) perform(method, path string, params url.Values, v interface{}) error {
+	u := &url.URL{Path: path, RawQuery: params.Encode()}
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.es.Perform(req)
+	if err != nil {
+		return fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("request failed: [%d] %s", resp.StatusCode, body)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *Client) GetILMExplain(indices []string) (map[string]ILMIndexExplain, error) {
+	c.logger.Debug("Getting indices ILM explain: ", indices)
+	resp, err := c.es.ILM.ExplainLifecycle(strings.Join(indices, ","))
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		Indices map[string]ILMIndexExplain `json:"indices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.Indices, nil
+}
+
+func (c *Client) GetIndexTemplates() (map[string]IndexTemplate, error) {
+	c.logger.Debug("Getting index templates")
+	resp, err := c.es.Indices.GetIndexTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		IndexTemplates []struct {
+			Name          string        `json:"name"`
+			IndexTemplate IndexTemplate `json:"index_template"`
+		} `json:"index_templates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]IndexTemplate, len(r.IndexTemplates))
+	for _, t := range r.IndexTemplates {
+		templates[t.Name] = t.IndexTemplate
+	}
+
+	return templates, nil
+}
+
+// Return index settings resolved from the composable template and its component templates
+func (c *Client) SimulateTemplate(name string) (map[string]interface{}, error) {
+	c.logger.Debug("Simulating index template: ", name)
+	resp, err := c.es.Indices.SimulateTemplate(
+		c.es.Indices.SimulateTemplate.WithName(name),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		Template struct {
+			Settings map[string]interface{} `json:"settings"`
+		} `json:"template"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.Template.Settings, nil
+}
+
+func (c *Client) GetLegacyTemplates() (map[string]LegacyTemplate, error) {
+	c.logger.Debug("Getting legacy index templates")
+	resp, err := c.es.Indices.GetTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r map[string]LegacyTemplate
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (c *Client) GetFieldUsageStats(s []string) (map[string]FieldUsageStats, error) {
+	c.logger.Debug("Getting indices field usage stats: ", s)
+
+	var r map[string]json.RawMessage
+	if err := c.perform("GET", "/"+strings.Join(s, ",")+"/_field_usage_stats", nil, &r); err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]FieldUsageStats, len(r))
+	for index, v := range r {
+		if index == "_shards" {
+			continue
+		}
+		var s FieldUsageStats
+		if err := json.Unmarshal(v, &s); err != nil {
+			return nil, err
+		}
+		stats[index] = s
+	}
+
+	return stats, nil
+}
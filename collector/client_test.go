@@ -0,0 +1,48 @@
+package collector
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Elasticsearch 6.x doesn't report metadata versions, the filtered cluster state has only index states
+func TestGetMappingWithoutVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/_cluster/state"):
+			if !strings.Contains(r.URL.Query().Get("filter_path"), "metadata.indices.*.state") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"metadata": {"indices": {"logs-2024.01.01": {"state": "open"}}}}`))
+		case strings.HasSuffix(r.URL.Path, "/_mapping"):
+			_, _ = w.Write([]byte(`{"logs-2024.01.01": {"mappings": {"doc": {"properties": {"message": {"type": "text"}}}}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	client, err := NewClient(logger, []string{server.URL}, nil)
+	if err != nil {
+		t.Fatalf("error creating the client: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		mapping, err := client.GetMapping([]string{"*-2024.01.01"})
+		if err != nil {
+			t.Fatalf("GetMapping() error: %v", err)
+		}
+		if _, ok := mapping["logs-2024.01.01"]; !ok {
+			t.Errorf("GetMapping() = %v, want the mapping of logs-2024.01.01", mapping)
+		}
+	}
+}
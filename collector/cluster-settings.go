@@ -1,7 +1,10 @@
 package collector
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
@@ -11,17 +14,51 @@ type ClusterSettingsCollector struct {
 	client *Client
 	logger *logrus.Logger
 
+	// Patterns of settings exposed by the info metric
+	allow []string
+
+	mu       sync.Mutex
+	previous map[string]string
+	changes  map[string]float64
+
 	excludeExists            *prometheus.Desc
 	maxShardsPerNode         *prometheus.Desc
 	totalShardsPerNodeExists *prometheus.Desc
+	settingInfo              *prometheus.Desc
+	settingChanges           *prometheus.Desc
+	excludeValue             *prometheus.Desc
+	excludedNodeShards       *prometheus.Desc
+	excludedNodeBytes        *prometheus.Desc
 }
 
 func NewClusterSettingsCollector(logger *logrus.Logger, client *Client, labels, labels_group []string, datepattern string,
-	constLabels prometheus.Labels) *ClusterSettingsCollector {
+	allow []string, constLabels prometheus.Labels) *ClusterSettingsCollector {
 
 	return &ClusterSettingsCollector{
-		client: client,
-		logger: logger,
+		client:  client,
+		logger:  logger,
+		allow:   allow,
+		changes: map[string]float64{"persistent": 0, "transient": 0},
+		settingInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cluster_setting", "info"),
+			"Persistent and transient cluster settings", append(labels, "key", "value"), constLabels,
+		),
+		settingChanges: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cluster_settings", "changes_total"),
+			"Count of cluster settings changes between scrapes", labels, constLabels,
+		),
+		excludeValue: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "exclude", "value_info"),
+			"Value of the cluster.routing.allocation.exclude setting", append(labels, "attribute", "value"), constLabels,
+		),
+		excludedNodeShards: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "excluded_node", "shards"),
+			"Count of shards remaining on each node excluded from allocation", append(labels, "node"), constLabels,
+		),
+		excludedNodeBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "excluded_node", "shards_bytes"),
+			"Size of shards remaining on each node excluded from allocation", append(labels, "node"), constLabels,
+		),
 		excludeExists: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "exclude", "exists"),
 			"Exclude exists in cluster settings", labels, constLabels,
@@ -41,6 +78,11 @@ func (c *ClusterSettingsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.excludeExists
 	ch <- c.maxShardsPerNode
 	ch <- c.totalShardsPerNodeExists
+	ch <- c.settingInfo
+	ch <- c.settingChanges
+	ch <- c.excludeValue
+	ch <- c.excludedNodeShards
+	ch <- c.excludedNodeBytes
 }
 
 func (c *ClusterSettingsCollector) Collect(ch chan<- prometheus.Metric) {
@@ -49,6 +91,28 @@ func (c *ClusterSettingsCollector) Collect(ch chan<- prometheus.Metric) {
 		c.logger.Fatalf("error getting indices settings: %v", err)
 	}
 
+	current := make(map[string]string)
+	for _, section := range []string{"persistent", "transient"} {
+		if m, ok := settings[section].(map[string]interface{}); ok {
+			flattenSettings(m, section+".", current)
+		}
+	}
+
+	for k, v := range current {
+		section, key := splitSection(k)
+		if c.allowed(key) {
+			ch <- prometheus.MustNewConstMetric(c.settingInfo, prometheus.GaugeValue, 1, section, key, v)
+		}
+	}
+
+	c.trackChanges(current)
+	c.collectExcludedNodes(ch, current)
+	c.mu.Lock()
+	for section, v := range c.changes {
+		ch <- prometheus.MustNewConstMetric(c.settingChanges, prometheus.CounterValue, v, section)
+	}
+	c.mu.Unlock()
+
 	path := "persistent.cluster.routing.allocation.exclude"
 	if _, ok := walk(settings, path); ok {
 		ch <- prometheus.MustNewConstMetric(c.excludeExists, prometheus.CounterValue, 1, "persistent")
@@ -108,3 +172,217 @@ func (c *ClusterSettingsCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 
 }
+
+type allocationExclude struct {
+	section   string
+	attribute string
+	values    []string
+}
+
+// Return allocation exclusions of the flattened `section.key` cluster settings, with deduplicated values
+func allocationExcludes(current map[string]string) []allocationExclude {
+	const prefix = "cluster.routing.allocation.exclude."
+
+	var excludes []allocationExclude
+	for k, v := range current {
+		section, key := splitSection(k)
+		if !strings.HasPrefix(key, prefix) || v == "" {
+			continue
+		}
+
+		e := allocationExclude{section: section, attribute: strings.TrimPrefix(key, prefix)}
+		seen := make(map[string]bool)
+		for _, value := range strings.Split(v, ",") {
+			if value = strings.TrimSpace(value); value != "" && !seen[value] {
+				seen[value] = true
+				e.values = append(e.values, value)
+			}
+		}
+		excludes = append(excludes, e)
+	}
+
+	return excludes
+}
+
+// Report values of allocation exclusions, resolve them to nodes
+// and report shards which are still remaining on these nodes
+func (c *ClusterSettingsCollector) collectExcludedNodes(ch chan<- prometheus.Metric, current map[string]string) {
+	excludes := allocationExcludes(current)
+	for _, e := range excludes {
+		for _, value := range e.values {
+			ch <- prometheus.MustNewConstMetric(c.excludeValue, prometheus.GaugeValue, 1, e.section, e.attribute, value)
+		}
+	}
+
+	if len(excludes) == 0 {
+		return
+	}
+
+	nodes, err := c.client.GetNodes()
+	if err != nil {
+		c.logger.Errorf("error getting nodes info: %v", err)
+		return
+	}
+
+	// Excluded node name to the section, transient exclusions take precedence like in clusterSetting
+	excluded := make(map[string]string)
+	for id, node := range nodes {
+		for _, e := range excludes {
+			if e.matches(id, node) && excluded[node.Name] != "transient" {
+				excluded[node.Name] = e.section
+			}
+		}
+	}
+
+	if len(excluded) == 0 {
+		return
+	}
+
+	shards, err := c.client.GetAllShards()
+	if err != nil {
+		c.logger.Errorf("error getting shards: %v", err)
+		return
+	}
+
+	count := make(map[string]float64, len(excluded))
+	size := make(map[string]float64, len(excluded))
+	for node := range excluded {
+		count[node], size[node] = 0, 0
+	}
+	for _, shard := range shards {
+		if _, ok := excluded[shard.Node]; !ok {
+			continue
+		}
+		count[shard.Node]++
+		if v, err := strconv.ParseFloat(shard.Store, 64); err == nil {
+			size[shard.Node] += v
+		}
+	}
+
+	for node, section := range excluded {
+		ch <- prometheus.MustNewConstMetric(c.excludedNodeShards, prometheus.GaugeValue, count[node], section, node)
+		ch <- prometheus.MustNewConstMetric(c.excludedNodeBytes, prometheus.GaugeValue, size[node], section, node)
+	}
+}
+
+// Report whether the node matches any value of the exclusion
+func (e allocationExclude) matches(id string, node NodeInfo) bool {
+	var candidates []string
+	switch e.attribute {
+	case "_name":
+		candidates = []string{node.Name}
+	case "_id":
+		candidates = []string{id}
+	case "_ip", "_host_ip", "_publish_ip":
+		candidates = []string{node.IP}
+	case "_host":
+		candidates = []string{node.Host, node.IP}
+	case "_tier":
+		candidates = node.Roles
+	default:
+		if v, ok := node.Attributes[e.attribute]; ok {
+			candidates = []string{v}
+		}
+	}
+
+	for _, value := range e.values {
+		for _, candidate := range candidates {
+			if simpleMatch(value, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Compare settings with the previous scrape, count and log the changes
+func (c *ClusterSettingsCollector) trackChanges(current map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	defer func() { c.previous = current }()
+	if c.previous == nil {
+		return
+	}
+
+	changed := func(k, oldValue, newValue string) {
+		section, key := splitSection(k)
+		c.changes[section]++
+		c.logger.WithFields(logrus.Fields{
+			"section": section,
+			"key":     key,
+			"old":     oldValue,
+			"new":     newValue,
+		}).Info("Cluster setting changed")
+	}
+
+	for k, v := range current {
+		if old, ok := c.previous[k]; !ok || old != v {
+			changed(k, old, v)
+		}
+	}
+	for k, old := range c.previous {
+		if _, ok := current[k]; !ok {
+			changed(k, old, "")
+		}
+	}
+}
+
+func (c *ClusterSettingsCollector) allowed(key string) bool {
+	return matchAny(c.allow, key)
+}
+
+// Flatten nested settings into `a.b.c` keys, arrays are joined by comma
+func flattenSettings(m map[string]interface{}, prefix string, out map[string]string) {
+	for k, v := range m {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			flattenSettings(v, prefix+k+".", out)
+		case []interface{}:
+			values := make([]string, 0, len(v))
+			for _, s := range v {
+				values = append(values, fmt.Sprint(s))
+			}
+			out[prefix+k] = strings.Join(values, ",")
+		default:
+			out[prefix+k] = fmt.Sprint(v)
+		}
+	}
+}
+
+// Split `section.key` of the flattened settings
+func splitSection(k string) (string, string) {
+	i := strings.Index(k, ".")
+	return k[:i], k[i+1:]
+}
+
+// Look up the effective cluster setting: transient, then persistent, then defaults
+func clusterSetting(settings map[string]interface{}, path string) (string, bool) {
+	for _, section := range []string{"transient", "persistent", "defaults"} {
+		if m, ok := settings[section].(map[string]interface{}); ok {
+			if v, ok := lookupSetting(m, path); ok {
+				return fmt.Sprint(v), true
+			}
+		}
+	}
+	return "", false
+}
+
+// Look up the path in nested settings. Unlike walk, keys may contain dots themselves,
+// e.g. `flood_stage` and `flood_stage.frozen` are siblings.
+func lookupSetting(m map[string]interface{}, path string) (interface{}, bool) {
+	if v, ok := m[path]; ok {
+		return v, true
+	}
+	for i := range path {
+		if path[i] != '.' {
+			continue
+		}
+		if sub, ok := m[path[:i]].(map[string]interface{}); ok {
+			if v, ok := lookupSetting(sub, path[i+1:]); ok {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
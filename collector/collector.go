@@ -3,6 +3,8 @@ package collector
 import (
 	"crypto/tls"
 	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,10 +22,91 @@ var (
 	labels_health = []string{"index", "replicas"}
 	slabels       = []string{"repository"}
 	clabels       = []string{"section"}
+	cachelabels   = []string{"cache"}
+	nlabels       = []string{"node"}
+	slmlabels     = []string{"policy", "repository"}
+	oplabels      = []string{"repository", "snapshot", "type"}
 )
 
-func NewCollector(logger *logrus.Logger, address, project string, repo string, datepattern string, tlsClientConfig *tls.Config) error {
-	client, err := NewClient(logger, []string{address}, tlsClientConfig)
+type Options struct {
+	Address         string
+	Project         string
+	Repository      string
+	DatePattern     string
+	TLSClientConfig *tls.Config
+
+	// Monitor all repositories matching include and not matching exclude patterns
+	RepositoryDiscovery bool
+	RepositoryInclude   []string
+	RepositoryExclude   []string
+	// Count of the latest snapshots to report sizes and throughput of
+	SnapshotStatusCount int
+	// Interval of the repositories verification, 0 disables it
+	RepositoryVerifyInterval time.Duration
+
+	// Check snapshot coverage of dated indices of the last days in the repository
+	BackupCoverageDays   int
+	BackupCoverageMinAge time.Duration
+
+	// Size of a primary shard to count it as oversized, e.g. 50gb
+	ShardSizeThreshold string
+	// Count of the largest primary shards to report the size of, 0 disables it
+	ShardSizeTopN int
+
+	// Count of the most used fields to report, per index group
+	FieldUsageTopN int
+	// Index settings exposed as metrics, in the `path:type` form
+	SettingsMetrics []string
+
+	// Patterns of cluster settings exposed by the info metric
+	ClusterSettingsAllow []string
+
+	ILM           bool
+	TemplateDrift bool
+	SLM           bool
+	Progress      bool
+
+	// Explain allocation of the first unassigned primary shard of red indices
+	AllocationExplain bool
+	// Node attributes to report the skew of today's shards over, e.g. zone or rack
+	PlacementAttributes []string
+}
+
+func NewCollector(logger *logrus.Logger, opts Options) error {
+	datepattern := opts.DatePattern
+
+	settingsMetrics := make([]SettingMetric, 0, len(opts.SettingsMetrics))
+	for _, s := range opts.SettingsMetrics {
+		m, err := ParseSettingMetric(s)
+		if err != nil {
+			return err
+		}
+		settingsMetrics = append(settingsMetrics, m)
+	}
+
+	// Skew over nodes is always reported with the `node` attribute label
+	placementAttributes := make([]string, 0, len(opts.PlacementAttributes))
+	seen := make(map[string]bool)
+	for _, attr := range opts.PlacementAttributes {
+		if attr == "node" {
+			return fmt.Errorf("invalid placement attribute %q: the skew over nodes is always reported", attr)
+		}
+		if !seen[attr] {
+			seen[attr] = true
+			placementAttributes = append(placementAttributes, attr)
+		}
+	}
+
+	if opts.FieldUsageTopN < 0 {
+		return fmt.Errorf("invalid field usage top count %d: must not be negative", opts.FieldUsageTopN)
+	}
+
+	shardSizeThreshold, err := parseBytes(opts.ShardSizeThreshold)
+	if err != nil {
+		return fmt.Errorf("invalid shard size threshold %q: %v", opts.ShardSizeThreshold, err)
+	}
+
+	client, err := NewClient(logger, []string{opts.Address}, opts.TLSClientConfig)
 	if err != nil {
 		return fmt.Errorf("error creating the client: %v", err)
 	}
@@ -36,45 +119,161 @@ func NewCollector(logger *logrus.Logger, address, project string, repo string, d
 
 	cluster := info["cluster_name"].(string)
 
+	number, _ := walk(info, "version.number")
+	clusterVersion, err := parseVersion(fmt.Sprint(number))
+	if err != nil {
+		return fmt.Errorf("error parsing cluster version: %v", err)
+	}
+	client.version = clusterVersion
+
 	constLabels := prometheus.Labels{
 		"cluster": cluster,
-		"project": project,
+		"project": opts.Project,
 	}
 
-	err = prometheus.Register(NewFieldsCollector(logger, client, labels, labels_group, datepattern, constLabels))
+	fieldsCollector := NewFieldsCollector(logger, client, labels, labels_group, datepattern, constLabels)
+	err = prometheus.Register(fieldsCollector)
 	if err != nil {
 		return fmt.Errorf("error registering index fields count collector: %v", err)
 	}
+	http.HandleFunc("/fields/diff", fieldsCollector.ServeDiff)
 
-	err = prometheus.Register(NewIndicesCollector(logger, client, labels, labels_group, labels_health, datepattern, constLabels))
+	err = prometheus.Register(NewIndicesCollector(logger, client, labels, labels_group, labels_health, datepattern,
+		shardSizeThreshold, opts.ShardSizeTopN, constLabels))
 	if err != nil {
 		return fmt.Errorf("error registering indices stats collector: %v", err)
 	}
 
-	err = prometheus.Register(NewSettingsCollector(logger, client, labels, labels_group, datepattern, constLabels))
+	err = prometheus.Register(NewSettingsCollector(logger, client, labels, labels_group, datepattern, settingsMetrics, constLabels))
 	if err != nil {
 		return fmt.Errorf("error registering indices settings collector: %v", err)
 	}
 
-	err = prometheus.Register(NewClusterSettingsCollector(logger, client, clabels, labels_group, datepattern, constLabels))
+	err = prometheus.Register(NewClusterSettingsCollector(logger, client, clabels, labels_group, datepattern, opts.ClusterSettingsAllow, constLabels))
 	if err != nil {
 		return fmt.Errorf("error registering indices settings collector: %v", err)
 	}
 
-	if repo != "" {
-		err = prometheus.Register(NewSnapshotCollector(logger, client, repo, slabels, constLabels))
+	err = prometheus.Register(NewShardLimitsCollector(logger, client, labels, labels_group, nlabels, datepattern, constLabels))
+	if err != nil {
+		return fmt.Errorf("error registering shard limits collector: %v", err)
+	}
+
+	err = prometheus.Register(NewDiskCollector(logger, client, labels, nlabels, datepattern, constLabels))
+	if err != nil {
+		return fmt.Errorf("error registering disk watermark collector: %v", err)
+	}
+
+	err = prometheus.Register(NewCacheCollector(logger, client, cachelabels, constLabels))
+	if err != nil {
+		return fmt.Errorf("error registering metadata cache collector: %v", err)
+	}
+
+	err = prometheus.Register(NewAllocationCollector(logger, client, labels, datepattern, opts.AllocationExplain, constLabels))
+	if err != nil {
+		return fmt.Errorf("error registering shard allocation collector: %v", err)
+	}
+
+	err = prometheus.Register(NewPlacementCollector(logger, client, nlabels, datepattern, placementAttributes, constLabels))
+	if err != nil {
+		return fmt.Errorf("error registering shard placement collector: %v", err)
+	}
+
+	if opts.ILM {
+		err = prometheus.Register(NewILMCollector(logger, client, labels, labels_group, datepattern, constLabels))
+		if err != nil {
+			return fmt.Errorf("error registering ILM collector: %v", err)
+		}
+	}
+
+	if opts.TemplateDrift {
+		err = prometheus.Register(NewTemplateDriftCollector(logger, client, labels, datepattern, clusterVersion.atLeast(7, 9), constLabels))
+		if err != nil {
+			return fmt.Errorf("error registering index template drift collector: %v", err)
+		}
+	}
+
+	if opts.SLM {
+		err = prometheus.Register(NewSLMCollector(logger, client, slmlabels, constLabels))
+		if err != nil {
+			return fmt.Errorf("error registering SLM collector: %v", err)
+		}
+	}
+
+	if opts.Progress {
+		err = prometheus.Register(NewProgressCollector(logger, client, oplabels, constLabels))
+		if err != nil {
+			return fmt.Errorf("error registering snapshot progress collector: %v", err)
+		}
+	}
+
+	if clusterVersion.atLeast(7, 15) {
+		err = prometheus.Register(NewFieldUsageCollector(logger, client, labels_group, datepattern, opts.FieldUsageTopN, constLabels))
+		if err != nil {
+			return fmt.Errorf("error registering field usage collector: %v", err)
+		}
+	} else {
+		logger.Infof("Field usage stats are not supported by Elasticsearch %s, skipping the collector", clusterVersion)
+	}
+
+	if opts.Repository != "" || opts.RepositoryDiscovery {
+		err = prometheus.Register(NewSnapshotCollector(logger, client, opts.Repository, opts.RepositoryDiscovery,
+			opts.RepositoryInclude, opts.RepositoryExclude, opts.SnapshotStatusCount, slabels, constLabels))
 		if err != nil {
 			return fmt.Errorf("error registering snapshots stats collector: %v", err)
 		}
 	}
 
+	if (opts.Repository != "" || opts.RepositoryDiscovery) && opts.RepositoryVerifyInterval > 0 {
+		verifier := NewRepositoryVerifier(logger, client, opts.Repository, opts.RepositoryDiscovery,
+			opts.RepositoryInclude, opts.RepositoryExclude, opts.RepositoryVerifyInterval, slabels, constLabels)
+		err = prometheus.Register(verifier)
+		if err != nil {
+			return fmt.Errorf("error registering repository verifier: %v", err)
+		}
+		go verifier.Run()
+	}
+
+	if opts.Repository != "" && opts.BackupCoverageDays > 0 {
+		err = prometheus.Register(NewBackupCoverageCollector(logger, client, opts.Repository, datepattern,
+			opts.BackupCoverageDays, opts.BackupCoverageMinAge, constLabels))
+		if err != nil {
+			return fmt.Errorf("error registering backup coverage collector: %v", err)
+		}
+	}
+
 	return nil
 }
 
+type esVersion struct {
+	major, minor int
+}
+
+// Parse the `major.minor` part of the Elasticsearch version like 7.17.3 or 8.0.0-SNAPSHOT
+func parseVersion(s string) (esVersion, error) {
+	var v esVersion
+	if _, err := fmt.Sscanf(s, "%d.%d", &v.major, &v.minor); err != nil {
+		return v, fmt.Errorf("invalid version %q: %v", s, err)
+	}
+	return v, nil
+}
+
+func (v esVersion) atLeast(major, minor int) bool {
+	return v.major > major || v.major == major && v.minor >= minor
+}
+
+func (v esVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.major, v.minor)
+}
+
 func todayFunc(dp string) string {
 	return time.Now().Format(dp)
 }
 
+func yesterdayFunc(dp string) string {
+	return time.Now().AddDate(0, 0, -1).Format(dp)
+}
+
 func indicesPatternFunc(today string) string { return fmt.Sprintf("*-%s", today) }
 
 // Find date -Y.m.d (-2021.12.01) and replace
@@ -82,17 +281,53 @@ func indexGroupLabelFunc(index, today string) string {
 	return strings.ToLower(strings.TrimSuffix(index, "-"+today))
 }
 
-// Walk over the json map by path `f1.f2.f3` and return the last field
+// Match the string against the pattern with `*` wildcards
+func simpleMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+
+	for _, p := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, p)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(p):]
+	}
+
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+// Match the string against any of the patterns
+func matchAny(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if simpleMatch(p, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Walk over the json map by path `f1.f2.f3` and return the last field.
+// Reports false if any field of the path is missing or is not an object.
 func walk(m map[string]interface{}, path string) (interface{}, bool) {
 	p := strings.Split(path, ".")
 	for _, v := range p[:len(p)-1] {
-		if v, ok := m[v]; ok {
-			switch v := v.(type) {
-			case map[string]interface{}:
-				m = v
-			default:
-				return v, false
-			}
+		v, ok := m[v]
+		if !ok {
+			return nil, false
+		}
+		switch v := v.(type) {
+		case map[string]interface{}:
+			m = v
+		default:
+			return v, false
 		}
 	}
 
@@ -101,25 +336,61 @@ func walk(m map[string]interface{}, path string) (interface{}, bool) {
 	return v, ok
 }
 
-// Count mapping fields by `type` field
-// https://github.com/elastic/elasticsearch/issues/68947#issue-806860754
-func countFields(m map[string]interface{}, count float64) float64 {
-	if v, ok := m["type"]; ok {
-		switch v := v.(type) {
-		case map[string]interface{}:
-			count = countFields(v, count)
-		case string:
-			delete(m, "type")
-			count++
-		}
+var (
+	durationUnits = []struct {
+		suffix string
+		d      time.Duration
+	}{
+		{"nanos", time.Nanosecond},
+		{"micros", time.Microsecond},
+		{"ms", time.Millisecond},
+		{"s", time.Second},
+		{"m", time.Minute},
+		{"h", time.Hour},
+		{"d", 24 * time.Hour},
+	}
+	byteUnits = []struct {
+		suffix string
+		n      float64
+	}{
+		{"kb", 1 << 10},
+		{"mb", 1 << 20},
+		{"gb", 1 << 30},
+		{"tb", 1 << 40},
+		{"pb", 1 << 50},
+		{"b", 1},
 	}
+)
 
-	for _, v := range m {
-		switch v := v.(type) {
-		case map[string]interface{}:
-			count = countFields(v, count)
+// Parse Elasticsearch time value like `30s` or `500ms` into seconds, `-1` stays as is
+func parseDuration(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "-1" || s == "0" {
+		return strconv.ParseFloat(s, 64)
+	}
+	for _, u := range durationUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(s, u.suffix), 64)
+			if err != nil {
+				return 0, err
+			}
+			return v * u.d.Seconds(), nil
 		}
 	}
+	return 0, fmt.Errorf("invalid time value: %q", s)
+}
 
-	return count
+// Parse Elasticsearch byte size value like `512mb` or `1.5gb` into bytes, `-1` stays as is
+func parseBytes(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, u := range byteUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(s, u.suffix), 64)
+			if err != nil {
+				return 0, err
+			}
+			return v * u.n, nil
+		}
+	}
+	return strconv.ParseFloat(s, 64)
 }
@@ -0,0 +1,175 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const watermarkSettings = "cluster.routing.allocation.disk.watermark"
+
+var watermarks = []string{"low", "high", "flood_stage"}
+
+// Disk watermark is either the ratio of used disk space or the amount of free disk space in bytes
+type diskWatermark struct {
+	ratio float64
+	bytes float64
+}
+
+// Parse the disk watermark value like `85%`, `0.85` or `50gb`
+func parseWatermark(s string) (diskWatermark, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "%") {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		if err != nil {
+			return diskWatermark{}, fmt.Errorf("invalid watermark %q: %v", s, err)
+		}
+		return diskWatermark{ratio: v / 100}, nil
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil && v <= 1 {
+		return diskWatermark{ratio: v}, nil
+	}
+
+	v, err := parseBytes(s)
+	if err != nil {
+		return diskWatermark{}, fmt.Errorf("invalid watermark %q: %v", s, err)
+	}
+	return diskWatermark{bytes: v}, nil
+}
+
+// Return bytes which may be written to the disk before it reaches the watermark,
+// negative if the watermark is already exceeded
+func (w diskWatermark) headroom(total, available float64) float64 {
+	if w.bytes > 0 {
+		return available - w.bytes
+	}
+	return available - total*(1-w.ratio)
+}
+
+func (w diskWatermark) exceeded(total, available float64) bool {
+	return w.headroom(total, available) <= 0
+}
+
+type DiskCollector struct {
+	client *Client
+	logger *logrus.Logger
+
+	datePattern string
+
+	watermark       *prometheus.Desc
+	diskTotal       *prometheus.Desc
+	diskAvailable   *prometheus.Desc
+	headroom        *prometheus.Desc
+	shardsAboveHigh *prometheus.Desc
+}
+
+func NewDiskCollector(logger *logrus.Logger, client *Client, labels, labels_node []string, datepattern string,
+	constLabels prometheus.Labels) *DiskCollector {
+
+	return &DiskCollector{
+		client:      client,
+		logger:      logger,
+		datePattern: datepattern,
+		watermark: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "disk_watermark", "info"),
+			"Effective disk watermark settings", []string{"watermark", "value"}, constLabels,
+		),
+		diskTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node_disk", "total_bytes"),
+			"Total disk space of each node", labels_node, constLabels,
+		),
+		diskAvailable: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node_disk", "available_bytes"),
+			"Available disk space of each node", labels_node, constLabels,
+		),
+		headroom: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node_disk", "watermark_headroom_bytes"),
+			"Bytes which may be written to each node before it reaches the disk watermark, negative if exceeded",
+			append(labels_node, "watermark"), constLabels,
+		),
+		shardsAboveHigh: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index", "shards_above_high_watermark"),
+			"Count of shards of each index to date on nodes above the high disk watermark", append(labels, "node"), constLabels,
+		),
+	}
+}
+
+func (c *DiskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.watermark
+	ch <- c.diskTotal
+	ch <- c.diskAvailable
+	ch <- c.headroom
+	ch <- c.shardsAboveHigh
+}
+
+func (c *DiskCollector) Collect(ch chan<- prometheus.Metric) {
+	today := todayFunc(c.datePattern)
+	indicesPattern := indicesPatternFunc(today)
+
+	settings, err := c.client.GetClusterSettingsWithDefaults("*." + watermarkSettings)
+	if err != nil {
+		c.logger.Errorf("error getting disk watermark settings: %v", err)
+		return
+	}
+
+	levels := make(map[string]diskWatermark, len(watermarks))
+	for _, name := range watermarks {
+		path := watermarkSettings + "." + name
+		s, ok := clusterSetting(settings, path)
+		if !ok {
+			c.logger.Errorf("%q was not found", path)
+			continue
+		}
+		w, err := parseWatermark(s)
+		if err != nil {
+			c.logger.Errorf("error parsing %q value: %v", path, err)
+			continue
+		}
+		levels[name] = w
+		ch <- prometheus.MustNewConstMetric(c.watermark, prometheus.GaugeValue, 1, name, s)
+	}
+
+	fs, err := c.client.GetNodesFsStats()
+	if err != nil {
+		c.logger.Errorf("error getting nodes fs stats: %v", err)
+		return
+	}
+
+	aboveHigh := make(map[string]bool)
+	for _, n := range fs {
+		total, available := n.Fs.Total.TotalInBytes, n.Fs.Total.AvailableInBytes
+		ch <- prometheus.MustNewConstMetric(c.diskTotal, prometheus.GaugeValue, total, n.Name)
+		ch <- prometheus.MustNewConstMetric(c.diskAvailable, prometheus.GaugeValue, available, n.Name)
+
+		for name, w := range levels {
+			ch <- prometheus.MustNewConstMetric(c.headroom, prometheus.GaugeValue, w.headroom(total, available), n.Name, name)
+		}
+		if w, ok := levels["high"]; ok && w.exceeded(total, available) {
+			aboveHigh[n.Name] = true
+		}
+	}
+
+	if len(aboveHigh) == 0 {
+		return
+	}
+
+	shards, err := c.client.GetShards([]string{indicesPattern})
+	if err != nil {
+		c.logger.Errorf("error getting shards: %v", err)
+		return
+	}
+
+	type indexNode struct{ index, node string }
+	counts := make(map[indexNode]float64)
+	for _, shard := range shards {
+		if aboveHigh[shard.Node] {
+			counts[indexNode{shard.Index, shard.Node}]++
+		}
+	}
+	for k, v := range counts {
+		ch <- prometheus.MustNewConstMetric(c.shardsAboveHigh, prometheus.GaugeValue, v, k.index, indexGroupLabelFunc(k.index, today), k.node)
+	}
+}
@@ -0,0 +1,137 @@
+package collector
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+type FieldUsageCollector struct {
+	client *Client
+	logger *logrus.Logger
+
+	datePattern string
+	topN        int
+
+	mappedFields *prometheus.Desc
+	unusedFields *prometheus.Desc
+	topFields    *prometheus.Desc
+}
+
+func NewFieldUsageCollector(logger *logrus.Logger, client *Client, labels_group []string, datepattern string, topN int,
+	constLabels prometheus.Labels) *FieldUsageCollector {
+
+	return &FieldUsageCollector{
+		client:      client,
+		logger:      logger,
+		datePattern: datepattern,
+		topN:        topN,
+		mappedFields: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "field_usage", "mapped_fields"),
+			"Count of mapped data fields of each index group to date", labels_group, constLabels,
+		),
+		unusedFields: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "field_usage", "unused_fields"),
+			"Count of mapped data fields of each index group which were not used by queries or aggregations to date", labels_group, constLabels,
+		),
+		topFields: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "field_usage", "top_fields"),
+			"Usage count of the most used fields of each index group by queries and aggregations to date", append(labels_group, "field"), constLabels,
+		),
+	}
+}
+
+func (c *FieldUsageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.mappedFields
+	ch <- c.unusedFields
+	ch <- c.topFields
+}
+
+func (c *FieldUsageCollector) Collect(ch chan<- prometheus.Metric) {
+	today := todayFunc(c.datePattern)
+	indicesPattern := indicesPatternFunc(today)
+
+	mapping, err := c.client.GetMapping([]string{indicesPattern})
+	if err != nil {
+		c.logger.Errorf("error getting indices mapping: %v", err)
+		return
+	}
+
+	stats, err := c.client.GetFieldUsageStats([]string{indicesPattern})
+	if err != nil {
+		c.logger.Errorf("error getting indices field usage stats: %v", err)
+		return
+	}
+
+	groupFields := make(map[string]map[string]bool)
+	for index, v := range mapping {
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			c.logger.Errorf("got invalid mapping for: %s", index)
+			continue
+		}
+		root, ok := mappingRoot(data)
+		if !ok {
+			c.logger.Errorf("got invalid mapping for: %s", index)
+			continue
+		}
+
+		indexGroup := indexGroupLabelFunc(index, today)
+		if _, ok := groupFields[indexGroup]; !ok {
+			groupFields[indexGroup] = make(map[string]bool)
+		}
+		for p := range dataFieldPaths(root) {
+			groupFields[indexGroup][p] = true
+		}
+	}
+
+	groupUsage := make(map[string]map[string]float64)
+	for index, s := range stats {
+		indexGroup := indexGroupLabelFunc(index, today)
+		if _, ok := groupUsage[indexGroup]; !ok {
+			groupUsage[indexGroup] = make(map[string]float64)
+		}
+		for _, shard := range s.Shards {
+			for field, usage := range shard.Stats.Fields {
+				groupUsage[indexGroup][field] += usage.Queries()
+			}
+		}
+	}
+
+	for indexGroup, fields := range groupFields {
+		usage := groupUsage[indexGroup]
+
+		var unused float64
+		for field := range fields {
+			if usage[field] == 0 {
+				unused++
+			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.mappedFields, prometheus.GaugeValue, float64(len(fields)), indexGroup)
+		ch <- prometheus.MustNewConstMetric(c.unusedFields, prometheus.GaugeValue, unused, indexGroup)
+	}
+
+	for indexGroup, usage := range groupUsage {
+		top := make([]string, 0, len(usage))
+		for field, v := range usage {
+			if v > 0 {
+				top = append(top, field)
+			}
+		}
+		sort.Slice(top, func(i, j int) bool {
+			if usage[top[i]] == usage[top[j]] {
+				return top[i] < top[j]
+			}
+			return usage[top[i]] > usage[top[j]]
+		})
+		if len(top) > c.topN {
+			top = top[:c.topN]
+		}
+
+		for _, field := range top {
+			ch <- prometheus.MustNewConstMetric(c.topFields, prometheus.GaugeValue, usage[field], indexGroup, field)
+		}
+	}
+}
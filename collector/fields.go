@@ -1,6 +1,10 @@
 package collector
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 )
@@ -11,6 +15,11 @@ type FieldsCollector struct {
 	datePattern      string
 	fieldsCount      *prometheus.Desc
 	fieldsGroupCount *prometheus.Desc
+	fieldsGroupDelta *prometheus.Desc
+	dynamic          *prometheus.Desc
+	objectDynamic    *prometheus.Desc
+	dynamicTemplates *prometheus.Desc
+	dynamicObjects   *prometheus.Desc
 }
 
 func NewFieldsCollector(logger *logrus.Logger, client *Client, labels, labels_group []string, datepattern string,
@@ -28,12 +37,37 @@ func NewFieldsCollector(logger *logrus.Logger, client *Client, labels, labels_gr
 			prometheus.BuildFQName(namespace, "fields_group_count", "total"),
 			"Total number of fields of each index group to date", labels_group, constLabels,
 		),
+		fieldsGroupDelta: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "fields_group_count", "delta"),
+			"Difference between the number of fields of each index group to date and the previous day", labels_group, constLabels,
+		),
+		dynamic: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mapping_dynamic", "info"),
+			"Dynamic mode of the root object of each index mapping to date", append(labels, "dynamic"), constLabels,
+		),
+		objectDynamic: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mapping_object_dynamic", "info"),
+			"Dynamic mode of the objects which set it explicitly in each index mapping to date", append(labels, "path", "dynamic"), constLabels,
+		),
+		dynamicTemplates: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mapping_dynamic_templates", "total"),
+			"Count of dynamic templates of each index mapping to date", labels, constLabels,
+		),
+		dynamicObjects: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mapping_dynamic_objects", "total"),
+			"Count of object paths which allow dynamic mapping in each index mapping to date", labels, constLabels,
+		),
 	}
 }
 
 func (c *FieldsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.fieldsCount
 	ch <- c.fieldsGroupCount
+	ch <- c.fieldsGroupDelta
+	ch <- c.dynamic
+	ch <- c.objectDynamic
+	ch <- c.dynamicTemplates
+	ch <- c.dynamicObjects
 }
 
 func (c *FieldsCollector) Collect(ch chan<- prometheus.Metric) {
@@ -53,16 +87,140 @@ func (c *FieldsCollector) Collect(ch chan<- prometheus.Metric) {
 		data, ok := v.(map[string]interface{})
 		if !ok {
 			c.logger.Errorf("got invalid mapping for: %s", index)
+			continue
+		}
+
+		root, ok := mappingRoot(data)
+		if !ok {
+			c.logger.Errorf("got invalid mapping for: %s", index)
+			continue
 		}
 
-		count := countFields(data, 0)
+		count := countFields(root)
 
 		ch <- prometheus.MustNewConstMetric(c.fieldsCount, prometheus.GaugeValue, count, index, indexGrouplabel)
 
 		fieldsGroupCount[indexGrouplabel] += count
+
+		mode, _ := dynamicMode(root, "true")
+		ch <- prometheus.MustNewConstMetric(c.dynamic, prometheus.GaugeValue, 1, index, indexGrouplabel, mode)
+
+		objects, dynamicObjects := mappingDynamic(root)
+		for _, o := range objects {
+			ch <- prometheus.MustNewConstMetric(c.objectDynamic, prometheus.GaugeValue, 1, index, indexGrouplabel, o.path, o.mode)
+		}
+		ch <- prometheus.MustNewConstMetric(c.dynamicObjects, prometheus.GaugeValue, float64(dynamicObjects), index, indexGrouplabel)
+
+		templates, _ := root["dynamic_templates"].([]interface{})
+		ch <- prometheus.MustNewConstMetric(c.dynamicTemplates, prometheus.GaugeValue, float64(len(templates)), index, indexGrouplabel)
 	}
 
 	for indexGroup, v := range fieldsGroupCount {
 		ch <- prometheus.MustNewConstMetric(c.fieldsGroupCount, prometheus.GaugeValue, v, indexGroup)
 	}
+
+	yesterday := yesterdayFunc(c.datePattern)
+	yesterdayMapping, err := c.client.GetMapping([]string{indicesPatternFunc(yesterday)})
+	if err != nil {
+		c.logger.Errorf("error getting previous day indices mapping: %v", err)
+		return
+	}
+
+	yesterdayGroupCount := make(map[string]float64)
+	for index, v := range yesterdayMapping {
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			c.logger.Errorf("got invalid mapping for: %s", index)
+			continue
+		}
+		root, ok := mappingRoot(data)
+		if !ok {
+			c.logger.Errorf("got invalid mapping for: %s", index)
+			continue
+		}
+		yesterdayGroupCount[indexGroupLabelFunc(index, yesterday)] += countFields(root)
+	}
+
+	for indexGroup, v := range fieldsGroupCount {
+		if y, ok := yesterdayGroupCount[indexGroup]; ok {
+			ch <- prometheus.MustNewConstMetric(c.fieldsGroupDelta, prometheus.GaugeValue, v-y, indexGroup)
+		}
+	}
+}
+
+type fieldsDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// Serve added and removed field paths of each index group between the previous day and today.
+// The `index_group` query parameter limits the output to a single group.
+func (c *FieldsCollector) ServeDiff(w http.ResponseWriter, r *http.Request) {
+	today := todayFunc(c.datePattern)
+	yesterday := yesterdayFunc(c.datePattern)
+
+	todayFields, err := c.groupFieldPaths(today)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error getting indices mapping: %s", err), http.StatusBadGateway)
+		return
+	}
+	yesterdayFields, err := c.groupFieldPaths(yesterday)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error getting previous day indices mapping: %s", err), http.StatusBadGateway)
+		return
+	}
+
+	indexGroup := r.URL.Query().Get("index_group")
+	groups := make(map[string]fieldsDiff)
+	for group, fields := range todayFields {
+		if indexGroup != "" && group != indexGroup {
+			continue
+		}
+		if y, ok := yesterdayFields[group]; ok {
+			groups[group] = fieldsDiff{
+				Added:   missingPaths(fields, y),
+				Removed: missingPaths(y, fields),
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"today":     today,
+		"yesterday": yesterday,
+		"groups":    groups,
+	})
+	if err != nil {
+		c.logger.Debugf("Failed to write to stream: %v", err)
+	}
+}
+
+// Return field paths of each index group for the day
+func (c *FieldsCollector) groupFieldPaths(day string) (map[string]map[string]bool, error) {
+	mapping, err := c.client.GetMapping([]string{indicesPatternFunc(day)})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]map[string]bool)
+	for index, v := range mapping {
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		root, ok := mappingRoot(data)
+		if !ok {
+			continue
+		}
+
+		indexGroup := indexGroupLabelFunc(index, day)
+		if _, ok := groups[indexGroup]; !ok {
+			groups[indexGroup] = make(map[string]bool)
+		}
+		for p := range fieldPaths(root) {
+			groups[indexGroup][p] = true
+		}
+	}
+
+	return groups, nil
 }
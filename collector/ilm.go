@@ -0,0 +1,110 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+type ILMCollector struct {
+	client *Client
+	logger *logrus.Logger
+
+	datePattern string
+
+	managed          *prometheus.Desc
+	info             *prometheus.Desc
+	phaseTime        *prometheus.Desc
+	indexError       *prometheus.Desc
+	errorInfo        *prometheus.Desc
+	unmanagedInGroup *prometheus.Desc
+}
+
+func NewILMCollector(logger *logrus.Logger, client *Client, labels, labels_group []string, datepattern string,
+	constLabels prometheus.Labels) *ILMCollector {
+
+	return &ILMCollector{
+		client:      client,
+		logger:      logger,
+		datePattern: datepattern,
+		managed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ilm_index", "managed"),
+			"Each index to date is managed by ILM", labels, constLabels,
+		),
+		info: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ilm_index", "info"),
+			"ILM policy, phase, action and step of each index to date", append(labels, "policy", "phase", "action", "step"), constLabels,
+		),
+		phaseTime: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ilm_index", "phase_time_seconds"),
+			"Time spent by each index to date in the current ILM phase", labels, constLabels,
+		),
+		indexError: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ilm_index", "error"),
+			"Each index to date is in the ILM ERROR step", labels, constLabels,
+		),
+		errorInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ilm_index", "error_info"),
+			"Failed ILM step and the reason of each index to date in the ERROR step", append(labels, "failed_step", "reason"), constLabels,
+		),
+		unmanagedInGroup: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ilm_indices_group", "unmanaged"),
+			"Count of indices without ILM policy of each index group to date", labels_group, constLabels,
+		),
+	}
+}
+
+func (c *ILMCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.managed
+	ch <- c.info
+	ch <- c.phaseTime
+	ch <- c.indexError
+	ch <- c.errorInfo
+	ch <- c.unmanagedInGroup
+}
+
+func (c *ILMCollector) Collect(ch chan<- prometheus.Metric) {
+	today := todayFunc(c.datePattern)
+	indicesPattern := indicesPatternFunc(today)
+
+	explain, err := c.client.GetILMExplain([]string{indicesPattern})
+	if err != nil {
+		c.logger.Errorf("error getting indices ILM explain: %v", err)
+		return
+	}
+
+	now := time.Now()
+	unmanagedInGroup := make(map[string]float64)
+	for index, e := range explain {
+		indexGrouplabel := indexGroupLabelFunc(index, today)
+
+		if !e.Managed {
+			ch <- prometheus.MustNewConstMetric(c.managed, prometheus.GaugeValue, 0, index, indexGrouplabel)
+			unmanagedInGroup[indexGrouplabel]++
+			continue
+		}
+		if _, ok := unmanagedInGroup[indexGrouplabel]; !ok {
+			unmanagedInGroup[indexGrouplabel] = 0
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.managed, prometheus.GaugeValue, 1, index, indexGrouplabel)
+		ch <- prometheus.MustNewConstMetric(c.info, prometheus.GaugeValue, 1, index, indexGrouplabel, e.Policy, e.Phase, e.Action, e.Step)
+
+		if e.PhaseTimeMillis > 0 {
+			phaseTime := now.Sub(time.Unix(0, int64(e.PhaseTimeMillis)*int64(time.Millisecond)))
+			ch <- prometheus.MustNewConstMetric(c.phaseTime, prometheus.GaugeValue, phaseTime.Seconds(), index, indexGrouplabel)
+		}
+
+		if e.Step == "ERROR" {
+			ch <- prometheus.MustNewConstMetric(c.indexError, prometheus.GaugeValue, 1, index, indexGrouplabel)
+			ch <- prometheus.MustNewConstMetric(c.errorInfo, prometheus.GaugeValue, 1, index, indexGrouplabel, e.FailedStep, e.StepInfo.Reason)
+		} else {
+			ch <- prometheus.MustNewConstMetric(c.indexError, prometheus.GaugeValue, 0, index, indexGrouplabel)
+		}
+	}
+
+	for indexGroup, v := range unmanagedInGroup {
+		ch <- prometheus.MustNewConstMetric(c.unmanagedInGroup, prometheus.GaugeValue, v, indexGroup)
+	}
+}
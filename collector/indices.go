@@ -1,6 +1,7 @@
 package collector
 
 import (
+	"sort"
 	"strconv"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -11,11 +12,21 @@ var (
 	indexGroupLastTotalBytes = make(map[string]float64)
 )
 
+type shardSize struct {
+	index string
+	shard string
+	size  float64
+}
+
 type IndicesCollector struct {
 	client *Client
 	logger *logrus.Logger
 
 	datePattern string
+	// Size of a primary shard to count it as oversized
+	shardSizeThreshold float64
+	// Count of the largest primary shards to report the size of, 0 disables per shard metrics
+	shardSizeTopN int
 
 	indexSize      *prometheus.Desc
 	indexTotalSize *prometheus.Desc
@@ -23,15 +34,22 @@ type IndicesCollector struct {
 	docsCount      *prometheus.Desc
 	shardsDocs     *prometheus.Desc
 	indexHealth    *prometheus.Desc
+
+	shardSize       *prometheus.Desc
+	groupShardSize  *prometheus.Desc
+	oversizedShards *prometheus.Desc
+	topShardSize    *prometheus.Desc
 }
 
 func NewIndicesCollector(logger *logrus.Logger, client *Client, labels, labels_group []string, labels_health []string, datepattern string,
-	constLabels prometheus.Labels) *IndicesCollector {
+	shardSizeThreshold float64, shardSizeTopN int, constLabels prometheus.Labels) *IndicesCollector {
 
 	return &IndicesCollector{
-		client:      client,
-		logger:      logger,
-		datePattern: datepattern,
+		client:             client,
+		logger:             logger,
+		datePattern:        datepattern,
+		shardSizeThreshold: shardSizeThreshold,
+		shardSizeTopN:      shardSizeTopN,
 		indexSize: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "indices_store", "size_bytes_primary"),
 			"Size of each index to date", labels, constLabels,
@@ -56,6 +74,22 @@ func NewIndicesCollector(logger *logrus.Logger, client *Client, labels, labels_g
 			prometheus.BuildFQName(namespace, "indices_health", "status"),
 			"Health status of each index: green=0,yellow=1,red=2", labels_health, constLabels,
 		),
+		shardSize: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "indices_primary_shard", "size_bytes"),
+			"Maximum, minimum and average size of primary shards of each index to date", append(labels, "stat"), constLabels,
+		),
+		groupShardSize: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "indices_group_primary_shard", "size_bytes"),
+			"Maximum, minimum and average size of primary shards of each index group to date", append(labels_group, "stat"), constLabels,
+		),
+		oversizedShards: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "indices_oversized_shards", "total"),
+			"Count of primary shards of each index to date above the size threshold", labels, constLabels,
+		),
+		topShardSize: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "indices_shard", "size_bytes"),
+			"Size of the largest primary shards of today's indices", append(labels, "shard"), constLabels,
+		),
 	}
 }
 
@@ -66,6 +100,10 @@ func (c *IndicesCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.indexGroupSize
 	ch <- c.shardsDocs
 	ch <- c.indexHealth
+	ch <- c.shardSize
+	ch <- c.groupShardSize
+	ch <- c.oversizedShards
+	ch <- c.topShardSize
 }
 
 func (c *IndicesCollector) Collect(ch chan<- prometheus.Metric) {
@@ -104,6 +142,8 @@ func (c *IndicesCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	indexGroupSize := make(map[string]float64, len(indices))
+	groupShards := make(map[string][]float64)
+	var shards []shardSize
 	for index, v := range indices {
 		// Create variable with index prefix
 		indexGrouplabel := indexGroupLabelFunc(index, today)
@@ -167,9 +207,92 @@ func (c *IndicesCollector) Collect(ch chan<- prometheus.Metric) {
 			c.logger.Errorf("%q was not found for: %s", path, index)
 		}
 
+		sizes := primaryShardSizes(data)
+		if len(sizes) == 0 {
+			c.logger.Errorf("primary shards stats were not found for: %s", index)
+			continue
+		}
+
+		var oversized float64
+		values := make([]float64, 0, len(sizes))
+		for shard, v := range sizes {
+			values = append(values, v)
+			shards = append(shards, shardSize{index: index, shard: shard, size: v})
+			if v > c.shardSizeThreshold {
+				oversized++
+			}
+		}
+		groupShards[indexGrouplabel] = append(groupShards[indexGrouplabel], values...)
+
+		for stat, v := range sizeStats(values) {
+			ch <- prometheus.MustNewConstMetric(c.shardSize, prometheus.GaugeValue, v, index, indexGrouplabel, stat)
+		}
+		ch <- prometheus.MustNewConstMetric(c.oversizedShards, prometheus.GaugeValue, oversized, index, indexGrouplabel)
+	}
+
+	for indexGroup, values := range groupShards {
+		for stat, v := range sizeStats(values) {
+			ch <- prometheus.MustNewConstMetric(c.groupShardSize, prometheus.GaugeValue, v, indexGroup, stat)
+		}
+	}
+
+	if c.shardSizeTopN > 0 {
+		sort.Slice(shards, func(i, j int) bool { return shards[i].size > shards[j].size })
+		if len(shards) > c.shardSizeTopN {
+			shards = shards[:c.shardSizeTopN]
+		}
+		for _, s := range shards {
+			ch <- prometheus.MustNewConstMetric(c.topShardSize, prometheus.GaugeValue, s.size,
+				s.index, indexGroupLabelFunc(s.index, today), s.shard)
+		}
 	}
 
 	for indexGroup, v := range indexGroupSize {
 		ch <- prometheus.MustNewConstMetric(c.indexGroupSize, prometheus.CounterValue, v, indexGroup)
 	}
 }
+
+// Return store sizes of primary shards from `shards` of the index stats, keyed by the shard number
+func primaryShardSizes(data map[string]interface{}) map[string]float64 {
+	sizes := make(map[string]float64)
+
+	shards, _ := data["shards"].(map[string]interface{})
+	for shard, v := range shards {
+		copies, _ := v.([]interface{})
+		for _, c := range copies {
+			stats, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if primary, _ := walk(stats, "routing.primary"); primary != true {
+				continue
+			}
+			if size, ok := walk(stats, "store.size_in_bytes"); ok {
+				if v, ok := size.(float64); ok {
+					sizes[shard] = v
+				}
+			}
+		}
+	}
+
+	return sizes
+}
+
+// Return maximum, minimum and average of the values
+func sizeStats(values []float64) map[string]float64 {
+	stats := map[string]float64{"max": values[0], "min": values[0]}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+		if v > stats["max"] {
+			stats["max"] = v
+		}
+		if v < stats["min"] {
+			stats["min"] = v
+		}
+	}
+	stats["avg"] = sum / float64(len(values))
+
+	return stats
+}
@@ -0,0 +1,244 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Root level mapping parameters, used to tell the root object apart from the 6.x type layer
+var rootMappingParams = map[string]bool{
+	"properties":           true,
+	"dynamic":              true,
+	"dynamic_templates":    true,
+	"dynamic_date_formats": true,
+	"date_detection":       true,
+	"numeric_detection":    true,
+	"runtime":              true,
+	"enabled":              true,
+	"_source":              true,
+	"_meta":                true,
+	"_routing":             true,
+	"_field_names":         true,
+	"_all":                 true,
+	"_size":                true,
+}
+
+type objectDynamic struct {
+	path string
+	mode string
+}
+
+// Return the root object of the index mapping `{"mappings": {...}}`,
+// skipping the type layer (`_doc`) of Elasticsearch 6.x and its `_default_` mapping
+func mappingRoot(data map[string]interface{}) (map[string]interface{}, bool) {
+	mappings, ok := data["mappings"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	var types []map[string]interface{}
+	for k, v := range mappings {
+		if rootMappingParams[k] {
+			return mappings, true
+		}
+		if k == "_default_" {
+			continue
+		}
+		if v, ok := v.(map[string]interface{}); ok {
+			types = append(types, v)
+		}
+	}
+	if len(types) == 1 {
+		return types[0], true
+	}
+
+	return mappings, true
+}
+
+// Return the `dynamic` parameter of the object or the inherited value if it is not set
+func dynamicMode(m map[string]interface{}, inherited string) (string, bool) {
+	v, ok := m["dynamic"]
+	if !ok {
+		return inherited, false
+	}
+	return fmt.Sprint(v), true
+}
+
+// Report whether the mapping property is an object field
+func isObject(m map[string]interface{}) bool {
+	if t, ok := m["type"].(string); ok {
+		return t == "object" || t == "nested"
+	}
+	_, ok := m["properties"]
+	return ok
+}
+
+// Walk over object fields of the mapping and return the ones with explicitly set `dynamic`
+// and the count of object paths that allow dynamic mapping
+func mappingDynamic(root map[string]interface{}) ([]objectDynamic, int) {
+	var (
+		objects []objectDynamic
+		count   int
+	)
+
+	var walkObjects func(m map[string]interface{}, prefix, inherited string)
+	walkObjects = func(m map[string]interface{}, prefix, inherited string) {
+		properties, _ := m["properties"].(map[string]interface{})
+		for name, v := range properties {
+			field, ok := v.(map[string]interface{})
+			if !ok || !isObject(field) {
+				continue
+			}
+			if enabled, ok := field["enabled"].(bool); ok && !enabled {
+				continue
+			}
+
+			path := prefix + name
+			mode, explicit := dynamicMode(field, inherited)
+			if explicit {
+				objects = append(objects, objectDynamic{path: path, mode: mode})
+			}
+			if mode == "true" || mode == "runtime" {
+				count++
+			}
+
+			walkObjects(field, path+".", mode)
+		}
+	}
+
+	mode, _ := dynamicMode(root, "true")
+	walkObjects(root, "", mode)
+
+	return objects, count
+}
+
+// Count mapping fields the way Elasticsearch does for `index.mapping.total_fields.limit`:
+// every field and object under properties, multi-fields, field aliases and runtime fields.
+// Metadata fields are not counted.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/mapping-settings-limit.html
+func countFields(root map[string]interface{}) float64 {
+	count := countProperties(root)
+
+	if runtime, ok := root["runtime"].(map[string]interface{}); ok {
+		count += float64(len(runtime))
+	}
+
+	return count
+}
+
+// Count fields under `properties` of the object, including nested objects and multi-fields
+func countProperties(m map[string]interface{}) float64 {
+	var count float64
+
+	properties, _ := m["properties"].(map[string]interface{})
+	for _, v := range properties {
+		field, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		count++
+		count += countProperties(field)
+		count += countMultiFields(field)
+	}
+
+	return count
+}
+
+// Count multi-fields declared under `fields` of the field
+func countMultiFields(field map[string]interface{}) float64 {
+	var count float64
+
+	fields, _ := field["fields"].(map[string]interface{})
+	for _, v := range fields {
+		if v, ok := v.(map[string]interface{}); ok {
+			count++
+			count += countMultiFields(v)
+		}
+	}
+
+	return count
+}
+
+// Return paths of all fields counted by countFields
+func fieldPaths(root map[string]interface{}) map[string]bool {
+	paths := make(map[string]bool)
+
+	var walkProperties func(m map[string]interface{}, prefix string)
+	walkProperties = func(m map[string]interface{}, prefix string) {
+		properties, _ := m["properties"].(map[string]interface{})
+		for name, v := range properties {
+			field, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			path := prefix + name
+			paths[path] = true
+			walkMultiFields(field, path, paths)
+			walkProperties(field, path+".")
+		}
+	}
+	walkProperties(root, "")
+
+	if runtime, ok := root["runtime"].(map[string]interface{}); ok {
+		for name := range runtime {
+			paths[name] = true
+		}
+	}
+
+	return paths
+}
+
+func walkMultiFields(field map[string]interface{}, path string, paths map[string]bool) {
+	fields, _ := field["fields"].(map[string]interface{})
+	for name, v := range fields {
+		if v, ok := v.(map[string]interface{}); ok {
+			paths[path+"."+name] = true
+			walkMultiFields(v, path+"."+name, paths)
+		}
+	}
+}
+
+// Return paths of fields which hold data: no objects, aliases and runtime fields
+func dataFieldPaths(root map[string]interface{}) map[string]bool {
+	paths := make(map[string]bool)
+
+	var walkProperties func(m map[string]interface{}, prefix string)
+	walkProperties = func(m map[string]interface{}, prefix string) {
+		properties, _ := m["properties"].(map[string]interface{})
+		for name, v := range properties {
+			field, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			path := prefix + name
+			if isObject(field) {
+				walkProperties(field, path+".")
+				continue
+			}
+			if t, _ := field["type"].(string); t == "alias" {
+				continue
+			}
+			paths[path] = true
+			walkMultiFields(field, path, paths)
+		}
+	}
+	walkProperties(root, "")
+
+	return paths
+}
+
+// Return sorted paths which are present in a and missing in b
+func missingPaths(a, b map[string]bool) []string {
+	paths := make([]string, 0)
+	for p := range a {
+		if !b[p] {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	return paths
+}
@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCountFields(t *testing.T) {
+	tests := []struct {
+		name string
+		// Response of `GET <index>/_mapping`
+		mapping string
+		want    float64
+	}{
+		{
+			name: "7.x typeless",
+			mapping: `{"logs-2024.01.01": {"mappings": {
+				"dynamic": "strict",
+				"properties": {
+					"@timestamp": {"type": "date"},
+					"message": {"type": "text", "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}},
+					"host": {"properties": {"name": {"type": "keyword"}, "ip": {"type": "ip"}}}
+				}
+			}}}`,
+			want: 6,
+		},
+		{
+			name: "6.x type layer",
+			mapping: `{"logs-2024.01.01": {"mappings": {"_doc": {
+				"properties": {
+					"@timestamp": {"type": "date"},
+					"message": {"type": "text"}
+				}
+			}}}}`,
+			want: 2,
+		},
+		{
+			name: "6.x type layer with _default_",
+			mapping: `{"logs-2024.01.01": {"mappings": {
+				"_default_": {"properties": {"@timestamp": {"type": "date"}}},
+				"doc": {
+					"properties": {
+						"@timestamp": {"type": "date"},
+						"message": {"type": "text"},
+						"level": {"type": "keyword"}
+					}
+				}
+			}}}`,
+			want: 3,
+		},
+		{
+			name: "properties named type",
+			mapping: `{"logs-2024.01.01": {"mappings": {
+				"properties": {
+					"type": {"type": "keyword"},
+					"event": {"properties": {"type": {"type": "keyword"}, "kind": {"type": "keyword"}}}
+				}
+			}}}`,
+			want: 4,
+		},
+		{
+			name: "6.x properties named type",
+			mapping: `{"logs-2024.01.01": {"mappings": {"doc": {
+				"properties": {
+					"type": {"type": "keyword"},
+					"event": {"properties": {"type": {"type": "keyword"}}}
+				}
+			}}}}`,
+			want: 3,
+		},
+		{
+			name: "multi-fields",
+			mapping: `{"logs-2024.01.01": {"mappings": {
+				"properties": {
+					"city": {"type": "text", "fields": {
+						"raw": {"type": "keyword"},
+						"english": {"type": "text", "analyzer": "english"}
+					}}
+				}
+			}}}`,
+			want: 3,
+		},
+		{
+			name: "nested objects",
+			mapping: `{"logs-2024.01.01": {"mappings": {
+				"properties": {
+					"user": {"type": "nested", "properties": {
+						"first": {"type": "text", "fields": {"raw": {"type": "keyword"}}},
+						"last": {"type": "text"},
+						"address": {"type": "object", "properties": {"city": {"type": "keyword"}}}
+					}}
+				}
+			}}}`,
+			want: 6,
+		},
+		{
+			name: "aliases",
+			mapping: `{"logs-2024.01.01": {"mappings": {
+				"properties": {
+					"distance": {"type": "long"},
+					"route_length_miles": {"type": "alias", "path": "distance"}
+				}
+			}}}`,
+			want: 2,
+		},
+		{
+			name: "runtime fields",
+			mapping: `{"logs-2024.01.01": {"mappings": {
+				"runtime": {
+					"day_of_week": {"type": "keyword", "script": {"source": "emit(doc['@timestamp'].value.dayOfWeekEnum.toString())"}}
+				},
+				"properties": {
+					"@timestamp": {"type": "date"}
+				}
+			}}}`,
+			want: 2,
+		},
+		{
+			name: "disabled objects",
+			mapping: `{"logs-2024.01.01": {"mappings": {
+				"properties": {
+					"user_id": {"type": "keyword"},
+					"session_data": {"type": "object", "enabled": false}
+				}
+			}}}`,
+			want: 2,
+		},
+		{
+			name:    "empty mapping",
+			mapping: `{"logs-2024.01.01": {"mappings": {}}}`,
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r map[string]map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.mapping), &r); err != nil {
+				t.Fatalf("invalid fixture: %v", err)
+			}
+
+			for index, data := range r {
+				root, ok := mappingRoot(data)
+				if !ok {
+					t.Fatalf("mapping root was not found for: %s", index)
+				}
+
+				if got := countFields(root); got != tt.want {
+					t.Errorf("countFields() = %v, want %v", got, tt.want)
+				}
+				// The mapping must be left intact for the following analysis
+				if got := countFields(root); got != tt.want {
+					t.Errorf("second countFields() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package collector
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+type nodePlacement struct {
+	primaries float64
+	shards    float64
+	store     float64
+}
+
+// Reports how shards of today's indices are spread over data nodes
+type PlacementCollector struct {
+	client *Client
+	logger *logrus.Logger
+
+	datePattern string
+	attributes  []string
+
+	primaries *prometheus.Desc
+	shards    *prometheus.Desc
+	store     *prometheus.Desc
+	skew      *prometheus.Desc
+}
+
+func NewPlacementCollector(logger *logrus.Logger, client *Client, labels_node []string, datepattern string, attributes []string,
+	constLabels prometheus.Labels) *PlacementCollector {
+
+	return &PlacementCollector{
+		client:      client,
+		logger:      logger,
+		datePattern: datepattern,
+		attributes:  attributes,
+		primaries: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node_today", "primaries"),
+			"Count of primary shards of today's indices on each data node", labels_node, constLabels,
+		),
+		shards: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node_today", "shards"),
+			"Count of shards of today's indices on each data node", labels_node, constLabels,
+		),
+		store: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node_today", "store_bytes"),
+			"Store size of shards of today's indices on each data node", labels_node, constLabels,
+		),
+		skew: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "today_shards", "skew_ratio"),
+			"Maximum to average ratio of primary or all shards of today's indices over values of the node attribute, "+
+				"the `node` attribute is the spread over nodes", []string{"attribute", "kind"}, constLabels,
+		),
+	}
+}
+
+func (c *PlacementCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.primaries
+	ch <- c.shards
+	ch <- c.store
+	ch <- c.skew
+}
+
+func (c *PlacementCollector) Collect(ch chan<- prometheus.Metric) {
+	today := todayFunc(c.datePattern)
+	indicesPattern := indicesPatternFunc(today)
+
+	nodes, err := c.client.GetNodes()
+	if err != nil {
+		c.logger.Errorf("error getting nodes info: %v", err)
+		return
+	}
+
+	shards, err := c.client.GetAllShards()
+	if err != nil {
+		c.logger.Errorf("error getting shards: %v", err)
+		return
+	}
+
+	placement := make(map[string]nodePlacement)
+	for _, node := range nodes {
+		if isDataNode(node) {
+			placement[node.Name] = nodePlacement{}
+		}
+	}
+	for _, shard := range shards {
+		if shard.Node == "" || !simpleMatch(indicesPattern, shard.Index) {
+			continue
+		}
+
+		p := placement[shard.Node]
+		p.shards++
+		if shard.Prirep == "p" {
+			p.primaries++
+		}
+		if v, err := strconv.ParseFloat(shard.Store, 64); err == nil {
+			p.store += v
+		}
+		placement[shard.Node] = p
+	}
+
+	for node, p := range placement {
+		ch <- prometheus.MustNewConstMetric(c.primaries, prometheus.GaugeValue, p.primaries, node)
+		ch <- prometheus.MustNewConstMetric(c.shards, prometheus.GaugeValue, p.shards, node)
+		ch <- prometheus.MustNewConstMetric(c.store, prometheus.GaugeValue, p.store, node)
+	}
+
+	attributes := make(map[string]string, len(nodes))
+	c.sendSkew(ch, "node", placement, func(node string) (string, bool) { return node, true })
+	for _, attr := range c.attributes {
+		for _, node := range nodes {
+			attributes[node.Name] = node.Attributes[attr]
+		}
+		c.sendSkew(ch, attr, placement, func(node string) (string, bool) {
+			v := attributes[node]
+			return v, v != ""
+		})
+	}
+}
+
+// Report the skew of shards over values of the node attribute, nodes without the attribute are skipped
+func (c *PlacementCollector) sendSkew(ch chan<- prometheus.Metric, attr string, placement map[string]nodePlacement,
+	value func(node string) (string, bool)) {
+
+	primaries := make(map[string]float64)
+	shards := make(map[string]float64)
+	for node, p := range placement {
+		v, ok := value(node)
+		if !ok {
+			continue
+		}
+		primaries[v] += p.primaries
+		shards[v] += p.shards
+	}
+	if len(shards) == 0 {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.skew, prometheus.GaugeValue, skewRatio(primaries), attr, "primaries")
+	ch <- prometheus.MustNewConstMetric(c.skew, prometheus.GaugeValue, skewRatio(shards), attr, "shards")
+}
+
+// Return the ratio of the maximum to the average value, 1 means an even spread
+func skewRatio(counts map[string]float64) float64 {
+	var highest, sum float64
+	for _, v := range counts {
+		sum += v
+		if v > highest {
+			highest = v
+		}
+	}
+	if sum == 0 {
+		return 1
+	}
+	return highest / (sum / float64(len(counts)))
+}
@@ -0,0 +1,176 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	operationSnapshot = "snapshot"
+	operationRestore  = "restore"
+
+	// Count of indices which recoveries are fetched in a single request, keeps the URL within http.max_initial_line_length
+	recoveryBatchSize = 20
+)
+
+type operationKey struct {
+	repository string
+	snapshot   string
+}
+
+type operationProgress struct {
+	bytesDone   float64
+	bytesTotal  float64
+	shardsDone  float64
+	shardsTotal float64
+	startTime   float64
+}
+
+// Reports progress of running snapshots and restores from snapshots
+type ProgressCollector struct {
+	client *Client
+	logger *logrus.Logger
+
+	bytesDone   *prometheus.Desc
+	bytesTotal  *prometheus.Desc
+	shardsDone  *prometheus.Desc
+	shardsTotal *prometheus.Desc
+	elapsed     *prometheus.Desc
+}
+
+func NewProgressCollector(logger *logrus.Logger, client *Client, labels []string,
+	constLabels prometheus.Labels) *ProgressCollector {
+
+	return &ProgressCollector{
+		client: client,
+		logger: logger,
+		bytesDone: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_operation", "bytes_done"),
+			"Bytes copied by each running snapshot or restore", labels, constLabels,
+		),
+		bytesTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_operation", "bytes_total"),
+			"Bytes to copy by each running snapshot or restore", labels, constLabels,
+		),
+		shardsDone: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_operation", "shards_done"),
+			"Count of completed shards of each running snapshot or restore", labels, constLabels,
+		),
+		shardsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_operation", "shards_total"),
+			"Count of shards of each running snapshot or restore", labels, constLabels,
+		),
+		elapsed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_operation", "elapsed_seconds"),
+			"Time since the start of each running snapshot or restore", labels, constLabels,
+		),
+	}
+}
+
+func (c *ProgressCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.bytesDone
+	ch <- c.bytesTotal
+	ch <- c.shardsDone
+	ch <- c.shardsTotal
+	ch <- c.elapsed
+}
+
+func (c *ProgressCollector) Collect(ch chan<- prometheus.Metric) {
+	now := float64(time.Now().UnixNano()) / 1e6
+
+	snapshots, err := c.client.GetRunningSnapshotsStatus()
+	if err != nil {
+		c.logger.Errorf("error getting running snapshots status: %v", err)
+	}
+	for _, s := range snapshots {
+		c.send(ch, operationSnapshot, operationKey{repository: s.Repository, snapshot: s.Snapshot}, operationProgress{
+			bytesDone:   s.Stats.Processed.SizeInBytes,
+			bytesTotal:  s.Stats.Incremental.SizeInBytes,
+			shardsDone:  s.ShardsStats.Done,
+			shardsTotal: s.ShardsStats.Total,
+			startTime:   s.Stats.StartTimeInMillis,
+		}, now)
+	}
+
+	restores, err := c.restoreProgress()
+	if err != nil {
+		c.logger.Errorf("error getting restores progress: %v", err)
+	}
+	for key, p := range restores {
+		c.send(ch, operationRestore, key, p, now)
+	}
+}
+
+func (c *ProgressCollector) send(ch chan<- prometheus.Metric, kind string, key operationKey, p operationProgress, now float64) {
+	ch <- prometheus.MustNewConstMetric(c.bytesDone, prometheus.GaugeValue, p.bytesDone, key.repository, key.snapshot, kind)
+	ch <- prometheus.MustNewConstMetric(c.bytesTotal, prometheus.GaugeValue, p.bytesTotal, key.repository, key.snapshot, kind)
+	ch <- prometheus.MustNewConstMetric(c.shardsDone, prometheus.GaugeValue, p.shardsDone, key.repository, key.snapshot, kind)
+	ch <- prometheus.MustNewConstMetric(c.shardsTotal, prometheus.GaugeValue, p.shardsTotal, key.repository, key.snapshot, kind)
+	if p.startTime > 0 {
+		ch <- prometheus.MustNewConstMetric(c.elapsed, prometheus.GaugeValue, (now-p.startTime)/1000, key.repository, key.snapshot, kind)
+	}
+}
+
+// Aggregate snapshot recoveries of indices which are being restored. Only active recoveries are requested
+// to find them, then all recoveries of these indices to count the completed shards too.
+func (c *ProgressCollector) restoreProgress() (map[operationKey]operationProgress, error) {
+	active, err := c.client.GetRecovery(nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var indices []string
+	for index, shards := range active {
+		for _, shard := range shards {
+			if shard.Type == "SNAPSHOT" {
+				indices = append(indices, index)
+				break
+			}
+		}
+	}
+	if len(indices) == 0 {
+		return nil, nil
+	}
+
+	recoveries := make(map[string][]ShardRecovery, len(indices))
+	for len(indices) > 0 {
+		n := recoveryBatchSize
+		if len(indices) < n {
+			n = len(indices)
+		}
+		batch, err := c.client.GetRecovery(indices[:n], false)
+		if err != nil {
+			return nil, err
+		}
+		for index, shards := range batch {
+			recoveries[index] = shards
+		}
+		indices = indices[n:]
+	}
+
+	result := make(map[operationKey]operationProgress)
+	for _, shards := range recoveries {
+		for _, shard := range shards {
+			if shard.Type != "SNAPSHOT" {
+				continue
+			}
+
+			key := operationKey{repository: shard.Source.Repository, snapshot: shard.Source.Snapshot}
+			p := result[key]
+			p.bytesDone += shard.Index.Size.RecoveredInBytes
+			p.bytesTotal += shard.Index.Size.TotalInBytes
+			p.shardsTotal++
+			if shard.Stage == "DONE" {
+				p.shardsDone++
+			}
+			if p.startTime == 0 || shard.StartTimeInMillis < p.startTime {
+				p.startTime = shard.StartTimeInMillis
+			}
+			result[key] = p
+		}
+	}
+
+	return result, nil
+}
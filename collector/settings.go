@@ -1,31 +1,77 @@
 package collector
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 )
 
+// Index block types, see `index.blocks.*` settings
+var indexBlocks = []string{"read_only", "read_only_allow_delete", "read", "write", "metadata"}
+
+const (
+	settingNumber   = "number"
+	settingBool     = "bool"
+	settingDuration = "duration"
+	settingBytes    = "bytes"
+	settingString   = "string"
+)
+
+// Index setting exposed as a metric, see ParseSettingMetric
+type SettingMetric struct {
+	Path string
+	Type string
+}
+
+// Parse the setting metric definition `path:type`, e.g. `index.refresh_interval:duration`.
+// The path is looked up in `settings` of the index and then in `defaults`.
+// Valid types: number, bool, duration (seconds), bytes, string (info metric with the value label).
+func ParseSettingMetric(s string) (SettingMetric, error) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return SettingMetric{}, fmt.Errorf("invalid setting metric %q: expected path:type", s)
+	}
+
+	m := SettingMetric{
+		Path: s[:i],
+		Type: s[i+1:],
+	}
+	switch m.Type {
+	case settingNumber, settingBool, settingDuration, settingBytes, settingString:
+	default:
+		return SettingMetric{}, fmt.Errorf("invalid setting metric %q: unknown type %q", s, m.Type)
+	}
+
+	return m, nil
+}
+
 type SettingsCollector struct {
 	client *Client
 	logger *logrus.Logger
 
 	datePattern string
+	metrics     []SettingMetric
 
-	fieldsLimit         *prometheus.Desc
-	fieldsGroupLimit    *prometheus.Desc
-	readOnlyAllowDelete *prometheus.Desc
-	readOnly            *prometheus.Desc
+	fieldsLimit      *prometheus.Desc
+	fieldsGroupLimit *prometheus.Desc
+	block            *prometheus.Desc
+	blockedGroup     *prometheus.Desc
+	blockFloodStage  *prometheus.Desc
+	setting          *prometheus.Desc
+	settingInfo      *prometheus.Desc
 }
 
 func NewSettingsCollector(logger *logrus.Logger, client *Client, labels, labels_group []string, datepattern string,
-	constLabels prometheus.Labels) *SettingsCollector {
+	metrics []SettingMetric, constLabels prometheus.Labels) *SettingsCollector {
 
 	return &SettingsCollector{
 		client:      client,
 		logger:      logger,
 		datePattern: datepattern,
+		metrics:     metrics,
 		fieldsLimit: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "fields_limit", "total"),
 			"Total limit of fields of each index to date", labels, constLabels,
@@ -34,13 +80,26 @@ func NewSettingsCollector(logger *logrus.Logger, client *Client, labels, labels_
 			prometheus.BuildFQName(namespace, "fields_group_limit", "total"),
 			"Total limit of fields of each index group to date", labels_group, constLabels,
 		),
-		readOnlyAllowDelete: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "read_only_allow_delete", "total"),
-			"State of the read_only_allow_delete field, which means that the index is in readonly mode", labels, constLabels,
+		block: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index", "block"),
+			"State of the index.blocks.* setting of each index to date", append(labels, "block"), constLabels,
+		),
+		blockedGroup: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "indices_group_blocked", "total"),
+			"Count of indices with any block of each index group to date", labels_group, constLabels,
+		),
+		blockFloodStage: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index_block", "flood_stage"),
+			"The read_only_allow_delete block of each index to date is likely set by the flood-stage watermark: "+
+				"the index has shards on a node above it", labels, constLabels,
+		),
+		setting: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index", "setting"),
+			"Value of the configured setting of each index to date, durations are in seconds", append(labels, "setting"), constLabels,
 		),
-		readOnly: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, "read_only", "total"),
-			"State of the read_only field, which means that the index is in readonly mode", labels, constLabels,
+		settingInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index_setting", "info"),
+			"Value of the configured string setting of each index to date", append(labels, "setting", "value"), constLabels,
 		),
 	}
 }
@@ -48,8 +107,11 @@ func NewSettingsCollector(logger *logrus.Logger, client *Client, labels, labels_
 func (c *SettingsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.fieldsLimit
 	ch <- c.fieldsGroupLimit
-	ch <- c.readOnlyAllowDelete
-	ch <- c.readOnly
+	ch <- c.block
+	ch <- c.blockedGroup
+	ch <- c.blockFloodStage
+	ch <- c.setting
+	ch <- c.settingInfo
 }
 
 func (c *SettingsCollector) Collect(ch chan<- prometheus.Metric) {
@@ -62,6 +124,9 @@ func (c *SettingsCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	fieldsGroupLimit := make(map[string]float64)
+	blockedGroup := make(map[string]float64)
+	indexGroups := make(map[string]string, len(settings))
+	var readOnlyAllowDelete []string
 	for index, v := range settings {
 		// Create variable with index prefix
 		indexGrouplabel := indexGroupLabelFunc(index, today)
@@ -72,72 +137,171 @@ func (c *SettingsCollector) Collect(ch chan<- prometheus.Metric) {
 			continue
 		}
 
-		skipFlag := false
-
-		path_limit := "index.mapping.total_fields.limit"
-		limit, ok := walk(data, "settings."+path_limit)
-		if !ok {
-			limit, ok = walk(data, "defaults."+path_limit)
-			if !ok {
-				c.logger.Errorf("%q was not found for: %s", path_limit, index)
-				skipFlag = true
+		path := "index.mapping.total_fields.limit"
+		if limit, ok := indexSetting(data, path); ok {
+			if v, err := parseSetting(settingNumber, limit); err == nil {
+				ch <- prometheus.MustNewConstMetric(c.fieldsLimit, prometheus.GaugeValue, v, index, indexGrouplabel)
+				fieldsGroupLimit[indexGrouplabel] += v
+			} else {
+				c.logger.Errorf("error parsing %q value for: %s: %v ", path, index, err)
 			}
+		} else {
+			c.logger.Errorf("%q was not found for: %s", path, index)
 		}
-		if !skipFlag {
-			if s, ok := limit.(string); ok {
-				if v, err := strconv.ParseFloat(s, 64); err == nil {
-					ch <- prometheus.MustNewConstMetric(c.fieldsLimit, prometheus.GaugeValue, v, index, indexGrouplabel)
-					fieldsGroupLimit[indexGrouplabel] += v
-				} else {
-					c.logger.Errorf("error parsing %q value for: %s: %v ", path_limit, index, err)
+
+		indexGroups[index] = indexGrouplabel
+		blocked := false
+		for _, block := range indexBlocks {
+			path := "index.blocks." + block
+
+			var v float64
+			if s, ok := indexSetting(data, path); ok {
+				if v, err = parseSetting(settingBool, s); err != nil {
+					c.logger.Errorf("error parsing %q value for: %s: %v ", path, index, err)
+					continue
 				}
-			} else {
-				c.logger.Errorf("got invalid %q value for: %s value: %#v", path_limit, index, limit)
 			}
-		}
+			ch <- prometheus.MustNewConstMetric(c.block, prometheus.GaugeValue, v, index, indexGrouplabel, block)
 
-		path_block := "index.blocks.read_only_allow_delete"
-		block, ok := walk(data, "settings."+path_block)
-		if !ok {
-			ch <- prometheus.MustNewConstMetric(c.readOnlyAllowDelete, prometheus.GaugeValue, 0, index, indexGrouplabel)
-		} else {
-			if s, ok := block.(string); ok {
-				if v, err := strconv.ParseBool(s); err == nil {
-					if v {
-						ch <- prometheus.MustNewConstMetric(c.readOnlyAllowDelete, prometheus.GaugeValue, 1, index, indexGrouplabel)
-					} else {
-						ch <- prometheus.MustNewConstMetric(c.readOnlyAllowDelete, prometheus.GaugeValue, 0, index, indexGrouplabel)
-					}
-				} else {
-					c.logger.Errorf("error parsing %q value for: %s: %v ", path_block, index, err)
+			if v > 0 {
+				blocked = true
+				if block == "read_only_allow_delete" {
+					readOnlyAllowDelete = append(readOnlyAllowDelete, index)
 				}
-			} else {
-				c.logger.Errorf("got invalid %q value for: %s value: %#v", path_block, index, limit)
 			}
 		}
+		if blocked {
+			blockedGroup[indexGrouplabel]++
+		} else if _, ok := blockedGroup[indexGrouplabel]; !ok {
+			blockedGroup[indexGrouplabel] = 0
+		}
 
-		path_roblock := "index.blocks.read_only"
-		roblock, ok := walk(data, "settings."+path_roblock)
-		if !ok {
-			ch <- prometheus.MustNewConstMetric(c.readOnly, prometheus.GaugeValue, 0, index, indexGrouplabel)
-		} else {
-			if s, ok := roblock.(string); ok {
-				if v, err := strconv.ParseBool(s); err == nil {
-					if v {
-						ch <- prometheus.MustNewConstMetric(c.readOnly, prometheus.GaugeValue, 1, index, indexGrouplabel)
-					} else {
-						ch <- prometheus.MustNewConstMetric(c.readOnly, prometheus.GaugeValue, 0, index, indexGrouplabel)
-					}
-				} else {
-					c.logger.Errorf("error parsing %q value for: %s: %v ", path_roblock, index, err)
-				}
-			} else {
-				c.logger.Errorf("got invalid %q value for: %s value: %#v", path_roblock, index, limit)
+		for _, m := range c.metrics {
+			s, ok := indexSetting(data, m.Path)
+			if !ok {
+				c.logger.Debugf("%q was not found for: %s", m.Path, index)
+				continue
+			}
+
+			if m.Type == settingString {
+				ch <- prometheus.MustNewConstMetric(c.settingInfo, prometheus.GaugeValue, 1, index, indexGrouplabel, m.Path, s)
+				continue
+			}
+
+			v, err := parseSetting(m.Type, s)
+			if err != nil {
+				c.logger.Errorf("error parsing %q value for: %s: %v ", m.Path, index, err)
+				continue
 			}
+			ch <- prometheus.MustNewConstMetric(c.setting, prometheus.GaugeValue, v, index, indexGrouplabel, m.Path)
 		}
 	}
 
 	for indexGroup, v := range fieldsGroupLimit {
 		ch <- prometheus.MustNewConstMetric(c.fieldsGroupLimit, prometheus.GaugeValue, v, indexGroup)
 	}
+
+	for indexGroup, v := range blockedGroup {
+		ch <- prometheus.MustNewConstMetric(c.blockedGroup, prometheus.GaugeValue, v, indexGroup)
+	}
+
+	floodStage := make(map[string]bool)
+	if len(readOnlyAllowDelete) > 0 {
+		floodStage, err = c.floodStageIndices(readOnlyAllowDelete)
+		if err != nil {
+			c.logger.Errorf("error checking flood-stage watermark: %v", err)
+			return
+		}
+	}
+	for index, indexGroup := range indexGroups {
+		var v float64
+		if floodStage[index] {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.blockFloodStage, prometheus.GaugeValue, v, index, indexGroup)
+	}
+}
+
+// Return indices which have shards on nodes above the flood-stage disk watermark
+func (c *SettingsCollector) floodStageIndices(indices []string) (map[string]bool, error) {
+	settings, err := c.client.GetClusterSettingsWithDefaults("*." + watermarkSettings + ".flood_stage")
+	if err != nil {
+		return nil, err
+	}
+	s, ok := clusterSetting(settings, watermarkSettings+".flood_stage")
+	if !ok {
+		return nil, fmt.Errorf("%q was not found", watermarkSettings+".flood_stage")
+	}
+	watermark, err := parseWatermark(s)
+	if err != nil {
+		return nil, err
+	}
+
+	fs, err := c.client.GetNodesFsStats()
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]bool)
+	for _, n := range fs {
+		if watermark.exceeded(n.Fs.Total.TotalInBytes, n.Fs.Total.AvailableInBytes) {
+			nodes[n.Name] = true
+		}
+	}
+
+	shards, err := c.client.GetShards(indices)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool)
+	for _, shard := range shards {
+		if nodes[shard.Node] {
+			result[shard.Index] = true
+		}
+	}
+
+	return result, nil
+}
+
+// Look up the index setting in `settings` and then in `defaults`
+func indexSetting(data map[string]interface{}, path string) (string, bool) {
+	v, ok := walk(data, "settings."+path)
+	if !ok {
+		v, ok = walk(data, "defaults."+path)
+		if !ok {
+			return "", false
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, s := range v {
+			values = append(values, fmt.Sprint(s))
+		}
+		return strings.Join(values, ","), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// Parse the setting value according to its type into the metric value
+func parseSetting(kind, s string) (float64, error) {
+	switch kind {
+	case settingNumber:
+		return strconv.ParseFloat(s, 64)
+	case settingBool:
+		v, err := strconv.ParseBool(s)
+		if err != nil || !v {
+			return 0, err
+		}
+		return 1, nil
+	case settingDuration:
+		return parseDuration(s)
+	case settingBytes:
+		return parseBytes(s)
+	default:
+		return 0, fmt.Errorf("unsupported setting type %q", kind)
+	}
 }
@@ -0,0 +1,342 @@
+package collector
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const totalShardsPerNode = "routing.allocation.total_shards_per_node"
+
+type ShardLimitsCollector struct {
+	client *Client
+	logger *logrus.Logger
+
+	datePattern string
+
+	// Indices which can't be allocated, logged only when the state changes
+	mu         sync.Mutex
+	infeasible map[string]bool
+
+	feasible           *prometheus.Desc
+	totalShardsPerNode *prometheus.Desc
+	eligibleNodes      *prometheus.Desc
+	nodeOpenShards     *prometheus.Desc
+	openShards         *prometheus.Desc
+	shardsLimit        *prometheus.Desc
+	groupShards        *prometheus.Desc
+	todayShards        *prometheus.Desc
+	projectedShards    *prometheus.Desc
+}
+
+func NewShardLimitsCollector(logger *logrus.Logger, client *Client, labels, labels_group, labels_node []string, datepattern string,
+	constLabels prometheus.Labels) *ShardLimitsCollector {
+
+	return &ShardLimitsCollector{
+		client:      client,
+		logger:      logger,
+		datePattern: datepattern,
+		infeasible:  make(map[string]bool),
+		feasible: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index_allocation", "feasible"),
+			"All shards of each index to date fit on the eligible data nodes within total_shards_per_node", labels, constLabels,
+		),
+		totalShardsPerNode: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index_allocation", "total_shards_per_node"),
+			"Effective total_shards_per_node limit of each index to date, -1 if unlimited", labels, constLabels,
+		),
+		eligibleNodes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index_allocation", "eligible_nodes"),
+			"Count of data nodes of the preferred tier of each index to date, which are not excluded from allocation", labels, constLabels,
+		),
+		nodeOpenShards: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "node", "open_shards"),
+			"Count of shards of open indices allocated to each node", labels_node, constLabels,
+		),
+		openShards: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cluster", "open_shards"),
+			"Count of shards of open indices in the cluster, including unassigned", nil, constLabels,
+		),
+		shardsLimit: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cluster", "shards_limit"),
+			"Effective cluster shards limit: max_shards_per_node multiplied by the count of data nodes", []string{"tier"}, constLabels,
+		),
+		groupShards: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "indices_group", "shards"),
+			"Count of shards including replicas of each index group to date", labels_group, constLabels,
+		),
+		todayShards: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cluster", "today_shards"),
+			"Count of shards including replicas added by indices to date", nil, constLabels,
+		),
+		projectedShards: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cluster", "projected_shards"),
+			"Projected count of shards in the cluster after the next day indices are created, based on indices to date", nil, constLabels,
+		),
+	}
+}
+
+func (c *ShardLimitsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.feasible
+	ch <- c.totalShardsPerNode
+	ch <- c.eligibleNodes
+	ch <- c.nodeOpenShards
+	ch <- c.openShards
+	ch <- c.shardsLimit
+	ch <- c.groupShards
+	ch <- c.todayShards
+	ch <- c.projectedShards
+}
+
+func (c *ShardLimitsCollector) Collect(ch chan<- prometheus.Metric) {
+	today := todayFunc(c.datePattern)
+	indicesPattern := indicesPatternFunc(today)
+
+	clusterSettings, err := c.client.GetClusterSettingsWithDefaults("*.cluster."+totalShardsPerNode, "*.cluster.max_shards_per_node*",
+		"*.cluster.routing.allocation.exclude")
+	if err != nil {
+		c.logger.Errorf("error getting cluster settings: %v", err)
+		return
+	}
+	clusterLimit := -1
+	if s, ok := clusterSetting(clusterSettings, "cluster."+totalShardsPerNode); ok {
+		if clusterLimit, err = strconv.Atoi(s); err != nil {
+			c.logger.Errorf("error parsing %q value: %v", "cluster."+totalShardsPerNode, err)
+			clusterLimit = -1
+		}
+	}
+
+	nodes, err := c.client.GetNodes()
+	if err != nil {
+		c.logger.Errorf("error getting nodes info: %v", err)
+		return
+	}
+
+	c.collectShardsLimit(ch, clusterSettings, nodes)
+
+	// Shards are never allocated to excluded nodes
+	current := make(map[string]string)
+	for _, section := range []string{"persistent", "transient"} {
+		if m, ok := clusterSettings[section].(map[string]interface{}); ok {
+			flattenSettings(m, section+".", current)
+		}
+	}
+	allocatable := allocatableNodes(nodes, allocationExcludes(current))
+
+	settings, err := c.client.GetSettings([]string{indicesPattern})
+	if err != nil {
+		c.logger.Errorf("error getting indices settings: %v", err)
+		return
+	}
+
+	groupShards := make(map[string]float64)
+	infeasible := make(map[string]bool)
+	for index, v := range settings {
+		indexGrouplabel := indexGroupLabelFunc(index, today)
+
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			c.logger.Errorf("got invalid index setttings for: %s", index)
+			continue
+		}
+
+		values := make(map[string]int)
+		for _, path := range []string{"index.number_of_shards", "index.number_of_replicas", "index." + totalShardsPerNode} {
+			s, ok := indexSetting(data, path)
+			if !ok {
+				values[path] = -1
+				continue
+			}
+			if values[path], err = strconv.Atoi(s); err != nil {
+				c.logger.Errorf("error parsing %q value for: %s: %v ", path, index, err)
+				values[path] = -1
+			}
+		}
+		shards, replicas := values["index.number_of_shards"], values["index.number_of_replicas"]
+		if shards < 0 || replicas < 0 {
+			continue
+		}
+		groupShards[indexGrouplabel] += float64(shards * (replicas + 1))
+
+		limit := values["index."+totalShardsPerNode]
+		if limit <= 0 || clusterLimit > 0 && clusterLimit < limit {
+			limit = clusterLimit
+		}
+
+		tierPreference, _ := indexSetting(data, "index.routing.allocation.include._tier_preference")
+		eligible := eligibleDataNodes(allocatable, tierPreference)
+
+		// Copies of the same shard are never allocated to the same node
+		feasible := replicas+1 <= eligible
+		if limit > 0 && limit*eligible < shards*(replicas+1) {
+			feasible = false
+		}
+
+		var v float64
+		if feasible {
+			v = 1
+		} else {
+			infeasible[index] = true
+			c.mu.Lock()
+			logged := c.infeasible[index]
+			c.mu.Unlock()
+			if !logged {
+				c.logger.Warnf("Shards of %s can't be allocated: %d shards, %d replicas, %d eligible nodes, total_shards_per_node %d",
+					index, shards, replicas, eligible, limit)
+			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.feasible, prometheus.GaugeValue, v, index, indexGrouplabel)
+		ch <- prometheus.MustNewConstMetric(c.totalShardsPerNode, prometheus.GaugeValue, float64(limit), index, indexGrouplabel)
+		ch <- prometheus.MustNewConstMetric(c.eligibleNodes, prometheus.GaugeValue, float64(eligible), index, indexGrouplabel)
+	}
+
+	c.mu.Lock()
+	c.infeasible = infeasible
+	c.mu.Unlock()
+
+	var todayShards float64
+	for indexGroup, v := range groupShards {
+		ch <- prometheus.MustNewConstMetric(c.groupShards, prometheus.GaugeValue, v, indexGroup)
+		todayShards += v
+	}
+	ch <- prometheus.MustNewConstMetric(c.todayShards, prometheus.GaugeValue, todayShards)
+
+	shards, err := c.client.GetAllShards()
+	if err != nil {
+		c.logger.Errorf("error getting shards: %v", err)
+		return
+	}
+
+	// Shards of closed indices don't count towards max_shards_per_node
+	closed, err := c.client.GetClosedIndices()
+	if err != nil {
+		c.logger.Errorf("error getting closed indices: %v", err)
+		return
+	}
+	var openShards float64
+
+	nodeShards := make(map[string]float64)
+	for _, node := range nodes {
+		if isDataNode(node) || hasRole(node, "data_frozen") {
+			nodeShards[node.Name] = 0
+		}
+	}
+	for _, shard := range shards {
+		if closed[shard.Index] {
+			continue
+		}
+		openShards++
+		if shard.Node != "" {
+			nodeShards[shard.Node]++
+		}
+	}
+	for node, v := range nodeShards {
+		ch <- prometheus.MustNewConstMetric(c.nodeOpenShards, prometheus.GaugeValue, v, node)
+	}
+
+	// Each day adds as many shards as the indices to date have
+	ch <- prometheus.MustNewConstMetric(c.openShards, prometheus.GaugeValue, openShards)
+	ch <- prometheus.MustNewConstMetric(c.projectedShards, prometheus.GaugeValue, openShards+todayShards)
+}
+
+// Report max_shards_per_node multiplied by the count of data nodes, for regular and frozen tiers
+func (c *ShardLimitsCollector) collectShardsLimit(ch chan<- prometheus.Metric, settings map[string]interface{}, nodes map[string]NodeInfo) {
+	var frozenNodes int
+	for _, node := range nodes {
+		if hasRole(node, "data_frozen") {
+			frozenNodes++
+		}
+	}
+
+	for _, l := range []struct {
+		tier  string
+		path  string
+		def   float64
+		nodes int
+	}{
+		{"normal", "cluster.max_shards_per_node", 1000, eligibleDataNodes(nodes, "")},
+		{"frozen", "cluster.max_shards_per_node.frozen", 3000, frozenNodes},
+	} {
+		limit := l.def
+		if s, ok := clusterSetting(settings, l.path); ok {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				c.logger.Errorf("error parsing %q value: %v", l.path, err)
+				continue
+			}
+			limit = v
+		}
+		ch <- prometheus.MustNewConstMetric(c.shardsLimit, prometheus.GaugeValue, limit*float64(l.nodes), l.tier)
+	}
+}
+
+// Return nodes which are not matched by any allocation exclusion
+func allocatableNodes(nodes map[string]NodeInfo, excludes []allocationExclude) map[string]NodeInfo {
+	result := make(map[string]NodeInfo, len(nodes))
+	for id, node := range nodes {
+		excluded := false
+		for _, e := range excludes {
+			if e.matches(id, node) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			result[id] = node
+		}
+	}
+
+	return result
+}
+
+// Count data nodes of the first tier of the preference list which has any nodes,
+// or all non-frozen data nodes if there is no preference
+func eligibleDataNodes(nodes map[string]NodeInfo, tierPreference string) int {
+	if tierPreference != "" {
+		for _, tier := range strings.Split(tierPreference, ",") {
+			tier = strings.TrimSpace(tier)
+
+			var count int
+			for _, node := range nodes {
+				if hasRole(node, "data") || hasRole(node, tier) {
+					count++
+				}
+			}
+			if count > 0 {
+				return count
+			}
+		}
+		return 0
+	}
+
+	var count int
+	for _, node := range nodes {
+		if isDataNode(node) {
+			count++
+		}
+	}
+	return count
+}
+
+// Report whether the node holds data of non-frozen tiers
+func isDataNode(node NodeInfo) bool {
+	for _, role := range node.Roles {
+		if strings.HasPrefix(role, "data") && role != "data_frozen" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRole(node NodeInfo, role string) bool {
+	for _, r := range node.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package collector
+
+import (
+	"encoding/json"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var slmOperationModes = []string{"RUNNING", "STOPPING", "STOPPED"}
+
+type SLMCollector struct {
+	client *Client
+	logger *logrus.Logger
+
+	lastSuccess      *prometheus.Desc
+	lastFailure      *prometheus.Desc
+	lastFailureInfo  *prometheus.Desc
+	nextExecution    *prometheus.Desc
+	snapshotsTaken   *prometheus.Desc
+	snapshotsFailed  *prometheus.Desc
+	snapshotsDeleted *prometheus.Desc
+	operationMode    *prometheus.Desc
+}
+
+func NewSLMCollector(logger *logrus.Logger, client *Client, labels []string,
+	constLabels prometheus.Labels) *SLMCollector {
+
+	return &SLMCollector{
+		client: client,
+		logger: logger,
+		lastSuccess: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "slm_policy", "last_success_timestamp_seconds"),
+			"Time of the last successful snapshot of each SLM policy", labels, constLabels,
+		),
+		lastFailure: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "slm_policy", "last_failure_timestamp_seconds"),
+			"Time of the last failed snapshot of each SLM policy", labels, constLabels,
+		),
+		lastFailureInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "slm_policy", "last_failure_info"),
+			"Reason of the last failed snapshot of each SLM policy", append(labels, "reason"), constLabels,
+		),
+		nextExecution: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "slm_policy", "next_execution_timestamp_seconds"),
+			"Time of the next scheduled execution of each SLM policy", labels, constLabels,
+		),
+		snapshotsTaken: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "slm_policy", "snapshots_taken_total"),
+			"Count of snapshots taken by each SLM policy", labels, constLabels,
+		),
+		snapshotsFailed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "slm_policy", "snapshots_failed_total"),
+			"Count of snapshots failed by each SLM policy", labels, constLabels,
+		),
+		snapshotsDeleted: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "slm_policy", "snapshots_deleted_total"),
+			"Count of snapshots deleted by retention of each SLM policy", labels, constLabels,
+		),
+		operationMode: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "slm", "operation_mode"),
+			"Current SLM operation mode", []string{"mode"}, constLabels,
+		),
+	}
+}
+
+func (c *SLMCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.lastSuccess
+	ch <- c.lastFailure
+	ch <- c.lastFailureInfo
+	ch <- c.nextExecution
+	ch <- c.snapshotsTaken
+	ch <- c.snapshotsFailed
+	ch <- c.snapshotsDeleted
+	ch <- c.operationMode
+}
+
+func (c *SLMCollector) Collect(ch chan<- prometheus.Metric) {
+	mode, err := c.client.GetSLMStatus()
+	if err != nil {
+		c.logger.Errorf("error getting SLM status: %v", err)
+	} else {
+		for _, m := range slmOperationModes {
+			var v float64
+			if m == mode {
+				v = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.operationMode, prometheus.GaugeValue, v, m)
+		}
+	}
+
+	policies, err := c.client.GetSLMPolicies()
+	if err != nil {
+		c.logger.Errorf("error getting SLM policies: %v", err)
+		return
+	}
+
+	for id, p := range policies {
+		repo := p.Policy.Repository
+		if p.LastSuccess != nil {
+			ch <- prometheus.MustNewConstMetric(c.lastSuccess, prometheus.GaugeValue, p.LastSuccess.Time/1000, id, repo)
+		}
+		if p.LastFailure != nil {
+			ch <- prometheus.MustNewConstMetric(c.lastFailure, prometheus.GaugeValue, p.LastFailure.Time/1000, id, repo)
+			ch <- prometheus.MustNewConstMetric(c.lastFailureInfo, prometheus.GaugeValue, 1, id, repo, failureReason(p.LastFailure.Details))
+		}
+		if p.NextExecutionMillis > 0 {
+			ch <- prometheus.MustNewConstMetric(c.nextExecution, prometheus.GaugeValue, p.NextExecutionMillis/1000, id, repo)
+		}
+	}
+
+	stats, err := c.client.GetSLMStats()
+	if err != nil {
+		c.logger.Errorf("error getting SLM stats: %v", err)
+		return
+	}
+
+	for _, s := range stats {
+		p, ok := policies[s.Policy]
+		if !ok {
+			continue
+		}
+		repo := p.Policy.Repository
+		ch <- prometheus.MustNewConstMetric(c.snapshotsTaken, prometheus.CounterValue, s.SnapshotsTaken, s.Policy, repo)
+		ch <- prometheus.MustNewConstMetric(c.snapshotsFailed, prometheus.CounterValue, s.SnapshotsFailed, s.Policy, repo)
+		ch <- prometheus.MustNewConstMetric(c.snapshotsDeleted, prometheus.CounterValue, s.SnapshotsDeleted, s.Policy, repo)
+	}
+}
+
+// Failure details are a serialized exception with the stack trace, keep only its reason
+func failureReason(details string) string {
+	var e struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(details), &e); err != nil || e.Reason == "" {
+		return details
+	}
+	return e.Reason
+}
@@ -0,0 +1,243 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// The first page is small since usually there are few new snapshots since the last scrape,
+	// pages grow up to the maximum size while no known snapshot is found
+	snapshotsFirstPageSize = 10
+	snapshotsMaxPageSize   = 500
+	// Count of snapshots fetched by name in a single request, keeps the URL within http.max_initial_line_length
+	snapshotsBatchSize = 20
+)
+
+type snapshotsPage struct {
+	Snapshots []Snapshot `json:"snapshots"`
+	Total     int        `json:"total"`
+	Next      string     `json:"next"`
+}
+
+// Incremental cache of snapshot summaries of each repository. Completed snapshots never change,
+// so only new and in-progress snapshots are fetched on each scrape, newest first.
+type snapshotCache struct {
+	mu    sync.Mutex
+	repos map[string]map[string]SnapshotSummary
+}
+
+func newSnapshotCache() *snapshotCache {
+	return &snapshotCache{repos: make(map[string]map[string]SnapshotSummary)}
+}
+
+func (c *snapshotCache) get(client *Client, repo string) ([]SnapshotSummary, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.repos[repo]
+	if !ok {
+		cached = make(map[string]SnapshotSummary)
+		c.repos[repo] = cached
+	}
+
+	refreshed := make(map[string]bool)
+	total, after, size := 0, "", snapshotsFirstPageSize
+	for {
+		page, err := client.getSnapshotsPage(repo, after, size)
+		if err != nil {
+			return nil, err
+		}
+		total = page.Total
+
+		known := false
+		for _, s := range page.Snapshots {
+			if prev, ok := cached[s.Snapshot]; ok && prev.State != "IN_PROGRESS" {
+				known = true
+				break
+			}
+			cached[s.Snapshot] = s.summary()
+			refreshed[s.Snapshot] = true
+		}
+
+		if known || page.Next == "" {
+			break
+		}
+		after = page.Next
+		if size *= 2; size > snapshotsMaxPageSize {
+			size = snapshotsMaxPageSize
+		}
+	}
+
+	// Snapshots which were in progress and started before the newest known completed one
+	var stale []string
+	for name, s := range cached {
+		if s.State == "IN_PROGRESS" && !refreshed[name] {
+			stale = append(stale, name)
+		}
+	}
+
+	// Some snapshots were deleted or missed, reconcile the cache with the list of names
+	if len(cached) != total {
+		names, err := client.getSnapshotNames(repo)
+		if err != nil {
+			return nil, err
+		}
+		for name := range cached {
+			if !names[name] {
+				delete(cached, name)
+			}
+		}
+		for name := range names {
+			if _, ok := cached[name]; !ok {
+				stale = append(stale, name)
+			}
+		}
+	}
+
+	for len(stale) > 0 {
+		n := snapshotsBatchSize
+		if len(stale) < n {
+			n = len(stale)
+		}
+		snapshots, err := client.getSnapshotsByName(repo, stale[:n])
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range snapshots {
+			cached[s.Snapshot] = s.summary()
+		}
+		stale = stale[n:]
+	}
+
+	result := make([]SnapshotSummary, 0, len(cached))
+	for _, s := range cached {
+		result = append(result, s)
+	}
+
+	return result, nil
+}
+
+// Return snapshots started since the time, with their indices. Since 7.14 only these snapshots are fetched,
+// newest first, with the paginated API.
+func (c *Client) GetSnapshotsSince(repo string, since time.Time) ([]Snapshot, error) {
+	sinceMillis := float64(since.UnixNano()) / 1e6
+
+	if !c.version.atLeast(7, 14) {
+		snapshots, err := c.getAllSnapshots(repo)
+		if err != nil {
+			return nil, err
+		}
+
+		var result []Snapshot
+		for _, s := range snapshots {
+			if s.StartTimeInMillis >= sinceMillis {
+				result = append(result, s)
+			}
+		}
+		return result, nil
+	}
+
+	var result []Snapshot
+	after, size := "", snapshotsFirstPageSize
+	for {
+		page, err := c.getSnapshotsPage(repo, after, size)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range page.Snapshots {
+			if s.StartTimeInMillis < sinceMillis {
+				return result, nil
+			}
+			result = append(result, s)
+		}
+
+		if page.Next == "" {
+			return result, nil
+		}
+		after = page.Next
+		if size *= 2; size > snapshotsMaxPageSize {
+			size = snapshotsMaxPageSize
+		}
+	}
+}
+
+// Return the page of snapshots sorted by start time, newest first.
+// Requires Elasticsearch 7.14+.
+func (c *Client) getSnapshotsPage(repo, after string, size int) (snapshotsPage, error) {
+	c.logger.Debug("Getting snapshots page in: ", repo)
+	params := url.Values{
+		"sort":  {"start_time"},
+		"order": {"desc"},
+		"size":  {strconv.Itoa(size)},
+	}
+	if after != "" {
+		params.Set("after", after)
+	}
+
+	var page snapshotsPage
+	err := c.perform("GET", fmt.Sprintf("/_snapshot/%s/*", url.PathEscape(repo)), params, &page)
+
+	return page, err
+}
+
+// Return names of all snapshots, without details
+func (c *Client) getSnapshotNames(repo string) (map[string]bool, error) {
+	c.logger.Debug("Getting snapshot names in: ", repo)
+	resp, err := c.es.Snapshot.Get(repo, []string{"*"},
+		c.es.Snapshot.Get.WithVerbose(false),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		Snapshots []struct {
+			Snapshot string `json:"snapshot"`
+		} `json:"snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(r.Snapshots))
+	for _, s := range r.Snapshots {
+		names[s.Snapshot] = true
+	}
+
+	return names, nil
+}
+
+func (c *Client) getSnapshotsByName(repo string, names []string) ([]Snapshot, error) {
+	c.logger.Debug("Getting snapshots in ", repo, ": ", names)
+	resp, err := c.es.Snapshot.Get(repo, names,
+		c.es.Snapshot.Get.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting response: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %v", resp.String())
+	}
+
+	var r struct {
+		Snapshots []Snapshot `json:"snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r.Snapshots, nil
+}
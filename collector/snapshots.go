@@ -1,43 +1,259 @@
 package collector
 
 import (
+	"sort"
+	"sync"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// Snapshot states reported even if there are no snapshots in them
+var snapshotStates = []string{"SUCCESS", "PARTIAL", "FAILED", "IN_PROGRESS"}
+
+// Single repository, or all repositories matching include and not matching exclude patterns
+type repositorySelector struct {
+	repo     string
+	discover bool
+	include  []string
+	exclude  []string
+}
+
+// Return names of the monitored repositories
+func (r repositorySelector) filter(logger *logrus.Logger, repos map[string]Repository) []string {
+	if !r.discover {
+		if _, ok := repos[r.repo]; !ok {
+			logger.Errorf("snapshot repository %q was not found", r.repo)
+			return nil
+		}
+		return []string{r.repo}
+	}
+
+	var names []string
+	for name := range repos {
+		if matchAny(r.include, name) && !matchAny(r.exclude, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 type SnapshotCollector struct {
 	client *Client
-	logger logrus.Logger
+	logger *logrus.Logger
+
+	repos repositorySelector
 
-	repo string
+	// Count of the latest snapshots to report the status of, statuses of completed snapshots are cached
+	statusCount int
+	mu          sync.Mutex
+	statusCache map[string]map[string]SnapshotStatus
 
-	snapshotsCount *prometheus.Desc
+	repositoryInfo     *prometheus.Desc
+	repositorySetting  *prometheus.Desc
+	snapshotsCount     *prometheus.Desc
+	snapshotsState     *prometheus.Desc
+	lastSuccess        *prometheus.Desc
+	lastDuration       *prometheus.Desc
+	latestFailedShards *prometheus.Desc
+	latestFailures     *prometheus.Desc
+	size               *prometheus.Desc
+	files              *prometheus.Desc
+	throughput         *prometheus.Desc
 }
 
-func NewSnapshotCollector(logger *logrus.Logger, client *Client, repo string, labels []string,
-	constLabels prometheus.Labels) *SnapshotCollector {
+func NewSnapshotCollector(logger *logrus.Logger, client *Client, repo string, discover bool, include, exclude []string,
+	statusCount int, labels []string, constLabels prometheus.Labels) *SnapshotCollector {
 
 	return &SnapshotCollector{
-		client: client,
-		logger: *logger,
-		repo:   repo,
+		client:      client,
+		logger:      logger,
+		repos:       repositorySelector{repo: repo, discover: discover, include: include, exclude: exclude},
+		statusCount: statusCount,
+		statusCache: make(map[string]map[string]SnapshotStatus),
+		repositoryInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_repository", "info"),
+			"Type of each monitored snapshot repository", append(labels, "type"), constLabels,
+		),
+		repositorySetting: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_repository", "setting_info"),
+			"Settings of each monitored snapshot repository", append(labels, "key", "value"), constLabels,
+		),
 		snapshotsCount: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "snapshots_count", "total"),
-			"Count of snapshots", slabels, constLabels,
+			"Count of snapshots", labels, constLabels,
+		),
+		snapshotsState: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshots", "state"),
+			"Count of snapshots in each state", append(labels, "state"), constLabels,
+		),
+		lastSuccess: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_last_success", "timestamp_seconds"),
+			"End time of the last successful snapshot", labels, constLabels,
+		),
+		lastDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_last", "duration_seconds"),
+			"Duration of the last completed snapshot", labels, constLabels,
+		),
+		latestFailedShards: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_latest", "failed_shards"),
+			"Count of failed shards in the latest snapshot", labels, constLabels,
+		),
+		latestFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_latest", "failures"),
+			"Count of failures in the latest snapshot", labels, constLabels,
+		),
+		size: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot", "size_bytes"),
+			"Total and incremental size of each latest snapshot", append(labels, "snapshot", "kind"), constLabels,
+		),
+		files: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot", "files"),
+			"Total and incremental count of files of each latest snapshot", append(labels, "snapshot", "kind"), constLabels,
+		),
+		throughput: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot", "throughput_bytes_per_second"),
+			"Incremental size of each latest snapshot divided by its duration", append(labels, "snapshot"), constLabels,
 		),
 	}
 }
 
 func (c *SnapshotCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.repositoryInfo
+	ch <- c.repositorySetting
 	ch <- c.snapshotsCount
+	ch <- c.snapshotsState
+	ch <- c.lastSuccess
+	ch <- c.lastDuration
+	ch <- c.latestFailedShards
+	ch <- c.latestFailures
+	ch <- c.size
+	ch <- c.files
+	ch <- c.throughput
 }
 
 func (c *SnapshotCollector) Collect(ch chan<- prometheus.Metric) {
-	snapshots, err := c.client.GetSnapshots(c.repo)
+	repos, err := c.client.GetRepositories()
+	if err != nil {
+		c.logger.Errorf("error getting snapshot repositories: %v", err)
+		return
+	}
+
+	for _, name := range c.repos.filter(c.logger, repos) {
+		repo := repos[name]
+		ch <- prometheus.MustNewConstMetric(c.repositoryInfo, prometheus.GaugeValue, 1, name, repo.Type)
+
+		settings := make(map[string]string)
+		flattenSettings(repo.Settings, "", settings)
+		for k, v := range settings {
+			ch <- prometheus.MustNewConstMetric(c.repositorySetting, prometheus.GaugeValue, 1, name, k, v)
+		}
+
+		c.collectRepository(ch, name)
+	}
+}
+
+func (c *SnapshotCollector) collectRepository(ch chan<- prometheus.Metric, repo string) {
+	snapshots, err := c.client.GetSnapshots(repo)
 	if err != nil {
-		c.logger.Fatalf("error getting snapshots count: %v", err)
+		c.logger.Errorf("error getting snapshots in %s: %v", repo, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.snapshotsCount, prometheus.GaugeValue, float64(len(snapshots)), repo)
+
+	states := make(map[string]float64, len(snapshotStates))
+	for _, state := range snapshotStates {
+		states[state] = 0
+	}
+
+	var latest, lastCompleted, lastSuccess *SnapshotSummary
+	for i := range snapshots {
+		s := &snapshots[i]
+		states[s.State]++
+
+		if latest == nil || s.StartTimeInMillis > latest.StartTimeInMillis {
+			latest = s
+		}
+		if s.State != "IN_PROGRESS" && (lastCompleted == nil || s.EndTimeInMillis > lastCompleted.EndTimeInMillis) {
+			lastCompleted = s
+		}
+		if s.State == "SUCCESS" && (lastSuccess == nil || s.EndTimeInMillis > lastSuccess.EndTimeInMillis) {
+			lastSuccess = s
+		}
+	}
+
+	for state, v := range states {
+		ch <- prometheus.MustNewConstMetric(c.snapshotsState, prometheus.GaugeValue, v, repo, state)
+	}
+	if lastSuccess != nil {
+		ch <- prometheus.MustNewConstMetric(c.lastSuccess, prometheus.GaugeValue, lastSuccess.EndTimeInMillis/1000, repo)
+	}
+	if lastCompleted != nil {
+		ch <- prometheus.MustNewConstMetric(c.lastDuration, prometheus.GaugeValue, lastCompleted.DurationInMillis/1000, repo)
+	}
+	if latest != nil {
+		ch <- prometheus.MustNewConstMetric(c.latestFailedShards, prometheus.GaugeValue, latest.Shards.Failed, repo)
+		ch <- prometheus.MustNewConstMetric(c.latestFailures, prometheus.GaugeValue, float64(latest.Failures), repo)
+	}
+
+	if c.statusCount > 0 {
+		c.collectStatus(ch, repo, snapshots)
+	}
+}
+
+// Report sizes and throughput of the latest snapshots
+func (c *SnapshotCollector) collectStatus(ch chan<- prometheus.Metric, repo string, snapshots []SnapshotSummary) {
+	latest := make([]SnapshotSummary, len(snapshots))
+	copy(latest, snapshots)
+	sort.Slice(latest, func(i, j int) bool { return latest[i].StartTimeInMillis > latest[j].StartTimeInMillis })
+	if len(latest) > c.statusCount {
+		latest = latest[:c.statusCount]
+	}
+
+	c.mu.Lock()
+	cached := c.statusCache[repo]
+	c.mu.Unlock()
+
+	statuses := make(map[string]SnapshotStatus, len(latest))
+	var missing []string
+	for _, s := range latest {
+		if status, ok := cached[s.Snapshot]; ok {
+			statuses[s.Snapshot] = status
+		} else {
+			missing = append(missing, s.Snapshot)
+		}
+	}
+
+	if len(missing) > 0 {
+		fetched, err := c.client.GetSnapshotsStatus(repo, missing)
+		if err != nil {
+			c.logger.Errorf("error getting snapshots status in %s: %v", repo, err)
+		}
+		for _, status := range fetched {
+			statuses[status.Snapshot] = status
+		}
+	}
+
+	// Keep only completed snapshots among the latest ones
+	completed := make(map[string]SnapshotStatus, len(latest))
+	for _, s := range latest {
+		if status, ok := statuses[s.Snapshot]; ok && s.State != "IN_PROGRESS" {
+			completed[s.Snapshot] = status
+		}
+	}
+	c.mu.Lock()
+	c.statusCache[repo] = completed
+	c.mu.Unlock()
+
+	for name, status := range statuses {
+		st := status.Stats
+		ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, st.Total.SizeInBytes, repo, name, "total")
+		ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, st.Incremental.SizeInBytes, repo, name, "incremental")
+		ch <- prometheus.MustNewConstMetric(c.files, prometheus.GaugeValue, st.Total.FileCount, repo, name, "total")
+		ch <- prometheus.MustNewConstMetric(c.files, prometheus.GaugeValue, st.Incremental.FileCount, repo, name, "incremental")
+		if st.TimeInMillis > 0 {
+			ch <- prometheus.MustNewConstMetric(c.throughput, prometheus.GaugeValue, st.Incremental.SizeInBytes/(st.TimeInMillis/1000), repo, name)
+		}
 	}
-	s := len(snapshots["snapshots"])
-	ch <- prometheus.MustNewConstMetric(c.snapshotsCount, prometheus.GaugeValue, float64(s), c.repo)
 }
@@ -0,0 +1,224 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Index settings compared with the matching index template
+var templateDriftSettings = []string{
+	"index.number_of_shards",
+	"index.number_of_replicas",
+	"index.mapping.total_fields.limit",
+	"index.lifecycle.name",
+}
+
+type templateVersion struct {
+	name    string
+	version int64
+}
+
+type TemplateDriftCollector struct {
+	client *Client
+	logger *logrus.Logger
+
+	datePattern string
+	// Composable templates and the simulate API are available since 7.9
+	composable bool
+
+	// Settings resolved from versioned composable templates, unversioned ones are simulated on each scrape
+	mu        sync.Mutex
+	simulated map[templateVersion]map[string]string
+
+	drift *prometheus.Desc
+}
+
+func NewTemplateDriftCollector(logger *logrus.Logger, client *Client, labels []string, datepattern string, composable bool,
+	constLabels prometheus.Labels) *TemplateDriftCollector {
+
+	return &TemplateDriftCollector{
+		client:      client,
+		logger:      logger,
+		datePattern: datepattern,
+		composable:  composable,
+		simulated:   make(map[templateVersion]map[string]string),
+		drift: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "index_template", "drift"),
+			"The setting of each index to date differs from its index template", append(labels, "setting"), constLabels,
+		),
+	}
+}
+
+func (c *TemplateDriftCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.drift
+}
+
+func (c *TemplateDriftCollector) Collect(ch chan<- prometheus.Metric) {
+	today := todayFunc(c.datePattern)
+	indicesPattern := indicesPatternFunc(today)
+
+	settings, err := c.client.GetSettings([]string{indicesPattern})
+	if err != nil {
+		c.logger.Errorf("error getting indices settings: %v", err)
+		return
+	}
+
+	var templates map[string]IndexTemplate
+	if c.composable {
+		templates, err = c.client.GetIndexTemplates()
+		if err != nil {
+			c.logger.Errorf("error getting index templates: %v", err)
+			return
+		}
+
+		c.mu.Lock()
+		for k := range c.simulated {
+			if _, ok := templates[k.name]; !ok {
+				delete(c.simulated, k)
+			}
+		}
+		c.mu.Unlock()
+	}
+	// Fetched lazily, only when an index has no matching composable template
+	var legacy map[string]LegacyTemplate
+	// Settings of the matched composable templates during this scrape
+	expectedByTemplate := make(map[string]map[string]string)
+
+	for index, v := range settings {
+		indexGrouplabel := indexGroupLabelFunc(index, today)
+
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			c.logger.Errorf("got invalid index setttings for: %s", index)
+			continue
+		}
+
+		var expected map[string]string
+		if name, ok := matchingIndexTemplate(templates, index); ok {
+			if expected, ok = expectedByTemplate[name]; !ok {
+				expected, err = c.resolveTemplate(name, templates[name])
+				if err != nil {
+					c.logger.Errorf("error simulating index template %s for: %s: %v", name, index, err)
+					continue
+				}
+				expectedByTemplate[name] = expected
+			}
+		} else {
+			if legacy == nil {
+				legacy, err = c.client.GetLegacyTemplates()
+				if err != nil {
+					c.logger.Errorf("error getting legacy index templates: %v", err)
+					return
+				}
+			}
+			expected = templateSettings(matchingLegacyTemplates(legacy, index))
+		}
+
+		for setting, want := range expected {
+			got, _ := indexSetting(data, setting)
+
+			var v float64
+			if got != want {
+				c.logger.Debugf("%q of %s differs from the template: %q != %q", setting, index, got, want)
+				v = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.drift, prometheus.GaugeValue, v, index, indexGrouplabel, setting)
+		}
+	}
+}
+
+// Return the compared settings resolved from the composable template, cached by the template version
+func (c *TemplateDriftCollector) resolveTemplate(name string, t IndexTemplate) (map[string]string, error) {
+	var key templateVersion
+	if t.Version != nil {
+		key = templateVersion{name: name, version: *t.Version}
+
+		c.mu.Lock()
+		expected, ok := c.simulated[key]
+		c.mu.Unlock()
+		if ok {
+			return expected, nil
+		}
+	}
+
+	settings, err := c.client.SimulateTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	expected := templateSettings([]map[string]interface{}{settings})
+
+	if t.Version != nil {
+		c.mu.Lock()
+		// Older versions of the template are not needed anymore
+		for k := range c.simulated {
+			if k.name == name {
+				delete(c.simulated, k)
+			}
+		}
+		c.simulated[key] = expected
+		c.mu.Unlock()
+	}
+
+	return expected, nil
+}
+
+// Return the name of the composable template with the highest priority matching the index
+func matchingIndexTemplate(templates map[string]IndexTemplate, index string) (string, bool) {
+	var (
+		matched  string
+		priority int64
+		found    bool
+	)
+	for name, t := range templates {
+		for _, p := range t.IndexPatterns {
+			if !simpleMatch(p, index) {
+				continue
+			}
+			if !found || t.Priority > priority || t.Priority == priority && name < matched {
+				matched, priority, found = name, t.Priority, true
+			}
+			break
+		}
+	}
+
+	return matched, found
+}
+
+// Return legacy templates matching the index, in the order they are applied
+func matchingLegacyTemplates(templates map[string]LegacyTemplate, index string) []map[string]interface{} {
+	var matched []LegacyTemplate
+	for _, t := range templates {
+		for _, p := range t.IndexPatterns {
+			if simpleMatch(p, index) {
+				matched = append(matched, t)
+				break
+			}
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Order < matched[j].Order })
+
+	settings := make([]map[string]interface{}, 0, len(matched))
+	for _, t := range matched {
+		settings = append(settings, t.Settings)
+	}
+
+	return settings
+}
+
+// Return the compared settings set by the templates, the later ones take precedence
+func templateSettings(templates []map[string]interface{}) map[string]string {
+	expected := make(map[string]string)
+	for _, t := range templates {
+		for _, setting := range templateDriftSettings {
+			if v, ok := walk(t, setting); ok {
+				expected[setting] = fmt.Sprint(v)
+			}
+		}
+	}
+
+	return expected
+}
@@ -0,0 +1,131 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+type verifyResult struct {
+	success   bool
+	duration  time.Duration
+	nodes     int
+	timestamp time.Time
+}
+
+// Verifies snapshot repositories on its own interval, separately from scrapes,
+// and reports the results of the last verification
+type RepositoryVerifier struct {
+	client *Client
+	logger *logrus.Logger
+
+	repos    repositorySelector
+	interval time.Duration
+
+	mu      sync.Mutex
+	results map[string]verifyResult
+
+	success   *prometheus.Desc
+	duration  *prometheus.Desc
+	nodes     *prometheus.Desc
+	timestamp *prometheus.Desc
+}
+
+func NewRepositoryVerifier(logger *logrus.Logger, client *Client, repo string, discover bool, include, exclude []string,
+	interval time.Duration, labels []string, constLabels prometheus.Labels) *RepositoryVerifier {
+
+	return &RepositoryVerifier{
+		client:   client,
+		logger:   logger,
+		repos:    repositorySelector{repo: repo, discover: discover, include: include, exclude: exclude},
+		interval: interval,
+		results:  make(map[string]verifyResult),
+		success: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_repository_verify", "success"),
+			"The last verification of the snapshot repository succeeded", labels, constLabels,
+		),
+		duration: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_repository_verify", "duration_seconds"),
+			"Duration of the last verification of the snapshot repository", labels, constLabels,
+		),
+		nodes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_repository_verify", "nodes"),
+			"Count of nodes which verified the snapshot repository", labels, constLabels,
+		),
+		timestamp: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "snapshot_repository_verify", "timestamp_seconds"),
+			"Time of the last verification of the snapshot repository", labels, constLabels,
+		),
+	}
+}
+
+// Verify repositories every interval, never returns
+func (v *RepositoryVerifier) Run() {
+	ticker := time.NewTicker(v.interval)
+	defer ticker.Stop()
+
+	for {
+		v.verify()
+		<-ticker.C
+	}
+}
+
+func (v *RepositoryVerifier) verify() {
+	start := time.Now()
+	repos, err := v.client.GetRepositories()
+	if err != nil {
+		v.logger.Errorf("error getting snapshot repositories: %v", err)
+
+		// The repositories can't be verified, don't keep reporting the previous successful results
+		v.mu.Lock()
+		for repo := range v.results {
+			v.results[repo] = verifyResult{duration: time.Since(start), timestamp: start}
+		}
+		v.mu.Unlock()
+		return
+	}
+
+	results := make(map[string]verifyResult)
+	for _, repo := range v.repos.filter(v.logger, repos) {
+		start := time.Now()
+		nodes, err := v.client.VerifyRepository(repo)
+		if err != nil {
+			v.logger.Errorf("snapshot repository %s verification failed: %v", repo, err)
+		}
+		results[repo] = verifyResult{
+			success:   err == nil,
+			duration:  time.Since(start),
+			nodes:     nodes,
+			timestamp: start,
+		}
+	}
+
+	v.mu.Lock()
+	v.results = results
+	v.mu.Unlock()
+}
+
+func (v *RepositoryVerifier) Describe(ch chan<- *prometheus.Desc) {
+	ch <- v.success
+	ch <- v.duration
+	ch <- v.nodes
+	ch <- v.timestamp
+}
+
+func (v *RepositoryVerifier) Collect(ch chan<- prometheus.Metric) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for repo, r := range v.results {
+		var success float64
+		if r.success {
+			success = 1
+		}
+		ch <- prometheus.MustNewConstMetric(v.success, prometheus.GaugeValue, success, repo)
+		ch <- prometheus.MustNewConstMetric(v.duration, prometheus.GaugeValue, r.duration.Seconds(), repo)
+		ch <- prometheus.MustNewConstMetric(v.nodes, prometheus.GaugeValue, float64(r.nodes), repo)
+		ch <- prometheus.MustNewConstMetric(v.timestamp, prometheus.GaugeValue, float64(r.timestamp.Unix()), repo)
+	}
+}
@@ -53,6 +53,57 @@ var (
 
 	projectName = kingpin.Flag("project", "Project name").String()
 	repoName    = kingpin.Flag("repository", "Repository name").String()
+
+	repoDiscovery = kingpin.Flag("repository.discover", "Monitor all snapshot repositories instead of the --repository one.").
+			Default("false").Bool()
+	repoInclude = kingpin.Flag("repository.include", "Pattern of discovered repositories to monitor, * is a wildcard. Can be repeated.").
+			Default("*").Strings()
+	repoExclude = kingpin.Flag("repository.exclude", "Pattern of discovered repositories to skip, * is a wildcard. Can be repeated.").
+			Strings()
+
+	repoVerifyInterval = kingpin.Flag("repository.verify-interval", "Interval of the snapshot repositories verification. 0 disables it.").
+				Default("0s").Duration()
+	snapshotStatusCount = kingpin.Flag("snapshot.status-count", "Count of the latest snapshots to report sizes and throughput of. 0 disables it.").
+				Default("0").Int()
+
+	backupCoverageDays = kingpin.Flag("backup-coverage.days",
+		"Count of days, starting from yesterday, to check dated indices are in a successful snapshot in --repository. 0 disables the check.",
+	).Default("0").Int()
+	backupCoverageMinAge = kingpin.Flag("backup-coverage.min-age", "Age after which an index not in any successful snapshot is unprotected.").
+				Default("24h").Duration()
+
+	fieldUsageTopN = kingpin.Flag("field-usage.top", "Count of the most used fields to report per index group.").
+			Default("10").Int()
+	settingsMetrics = kingpin.Flag("settings.metric",
+		"Index setting to expose as a metric in the path:type form. Valid types: [number, bool, duration, bytes, string]. Can be repeated.",
+	).Default(
+		"index.refresh_interval:duration",
+		"index.number_of_replicas:number",
+		"index.codec:string",
+		"index.translog.durability:string",
+	).Strings()
+	clusterSettingsAllow = kingpin.Flag("cluster-settings.allow",
+		"Pattern of persistent and transient cluster settings to expose as info metrics, * is a wildcard. Can be repeated.",
+	).Default("*").Strings()
+	shardSizeThreshold = kingpin.Flag("shard-size.threshold", "Size of a primary shard of today's index to count it as oversized.").
+				Default("50gb").String()
+	shardSizeTopN = kingpin.Flag("shard-size.top", "Count of the largest primary shards of today's indices to report the size of, 0 disables it.").
+			Default("0").Int()
+	allocationExplain = kingpin.Flag("allocation.explain",
+		"Explain allocation of the first unassigned primary shard of each red index to date.",
+	).Default("false").Bool()
+	placementAttributes = kingpin.Flag("placement.attribute",
+		"Node attribute to report the skew of today's shards over, e.g. zone or rack. Can be repeated.",
+	).Strings()
+
+	ilm = kingpin.Flag("collector.ilm", "Enable the ILM explain collector for today's indices.").
+		Default("false").Bool()
+	slm = kingpin.Flag("collector.slm", "Enable the snapshot lifecycle management policies collector.").
+		Default("false").Bool()
+	progress = kingpin.Flag("collector.progress", "Enable the collector of running snapshots and restores progress.").
+			Default("false").Bool()
+	templateDrift = kingpin.Flag("collector.template-drift", "Enable the collector comparing today's indices with their index templates.").
+			Default("false").Bool()
 )
 
 func main() {
@@ -90,6 +141,7 @@ func main() {
 <body>
 <h1>es-oneday-exporter</h1>
 <p><a href="` + *metricsPath + `">Metrics</a></p>
+<p><a href="/fields/diff">Fields diff</a></p>
 <p><i>` + version.Info() + `</i></p>
 </body>
 </html>`))
@@ -100,7 +152,31 @@ func main() {
 
 	tlsClientConfig := createTLSConfig(*cacert, *clientcert, *clientkey, *insecure)
 
-	err := collector.NewCollector(log, *address, *projectName, *repoName, *datePattern, tlsClientConfig)
+	err := collector.NewCollector(log, collector.Options{
+		Address:                  *address,
+		Project:                  *projectName,
+		Repository:               *repoName,
+		RepositoryDiscovery:      *repoDiscovery,
+		RepositoryInclude:        *repoInclude,
+		RepositoryExclude:        *repoExclude,
+		SnapshotStatusCount:      *snapshotStatusCount,
+		RepositoryVerifyInterval: *repoVerifyInterval,
+		BackupCoverageDays:       *backupCoverageDays,
+		BackupCoverageMinAge:     *backupCoverageMinAge,
+		DatePattern:              *datePattern,
+		TLSClientConfig:          tlsClientConfig,
+		ShardSizeThreshold:       *shardSizeThreshold,
+		ShardSizeTopN:            *shardSizeTopN,
+		FieldUsageTopN:           *fieldUsageTopN,
+		SettingsMetrics:          *settingsMetrics,
+		ClusterSettingsAllow:     *clusterSettingsAllow,
+		ILM:                      *ilm,
+		TemplateDrift:            *templateDrift,
+		SLM:                      *slm,
+		Progress:                 *progress,
+		AllocationExplain:        *allocationExplain,
+		PlacementAttributes:      *placementAttributes,
+	})
 	if err != nil {
 		log.Fatalf("error creating new collector instance: %v", err)
 	}